@@ -17,7 +17,7 @@ func HandleCommand(command string) error {
 		return execute([]string{}, "/usr/bin/sudo", "/sbin/shutdown", "-r", "now")
 	}
 	if command == "clear" {
-		images, e := files.ListFiles(conf.StorageFolder, true)
+		images, e := files.ListFiles(conf.StorageFolder, true, false)
 		if e != nil {
 			return e
 		}
@@ -28,8 +28,9 @@ func HandleCommand(command string) error {
 			}
 			fmt.Println("Removed file " + path)
 		}
+		return nil
 	}
-	return nil
+	return fmt.Errorf("unknown admin command: %s", command)
 }
 
 // RunCommand Execute and return the output to the caller.
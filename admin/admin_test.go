@@ -6,7 +6,7 @@ import (
 )
 
 func TestHandleCommand(t *testing.T) {
-	ensure.Nil(t, HandleCommand("non-exisinting"))
+	ensure.NotNil(t, HandleCommand("non-exisinting"))
 }
 
 func TestRunCommand(t *testing.T) {
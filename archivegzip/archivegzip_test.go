@@ -0,0 +1,59 @@
+package archivegzip
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	// Use a block size much smaller than the input so this exercises
+	// several parallel blocks, not just one.
+	var compressed bytes.Buffer
+	w := NewWriter(&compressed, gzip.DefaultCompression, 16, 4)
+
+	input := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog\n"), 200)
+	if _, err := w.Write(input); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close returned an error: %v", err)
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(compressed.Bytes()))
+	if err != nil {
+		t.Fatalf("gzip.NewReader returned an error: %v", err)
+	}
+	defer gr.Close()
+
+	got, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("failed to read decompressed output: %v", err)
+	}
+	if !bytes.Equal(got, input) {
+		t.Errorf("round-tripped content does not match input (got %d bytes, want %d)", len(got), len(input))
+	}
+}
+
+func TestWriterEmptyInput(t *testing.T) {
+	var compressed bytes.Buffer
+	w := NewWriter(&compressed, gzip.DefaultCompression, DefaultBlockSize, DefaultWorkers)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close returned an error: %v", err)
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(compressed.Bytes()))
+	if err != nil {
+		t.Fatalf("gzip.NewReader returned an error: %v", err)
+	}
+	defer gr.Close()
+
+	got, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("failed to read decompressed output: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected empty output, got %d bytes", len(got))
+	}
+}
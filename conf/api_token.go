@@ -0,0 +1,29 @@
+package conf
+
+import "github.com/boltdb/bolt"
+
+const apiTokenKey = "api-token"
+
+// StoreAPIToken persists the bearer token rest.TokenAuth checks incoming
+// requests against, keyed separately from Settings (and never included in
+// Settings.JSON) so it can't leak back out through GetConfiguration.
+func StoreAPIToken(token string) error {
+	db, err := bolt.Open(settingsFile, 0600, &bolt.Options{Timeout: boldIoTimeout})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return set(db, apiTokenKey, []byte(token))
+}
+
+// LoadAPIToken returns the previously stored API token, if any.
+func LoadAPIToken() (string, bool, error) {
+	db, err := bolt.Open(settingsFile, 0600, &bolt.Options{Timeout: boldIoTimeout})
+	if err != nil {
+		return "", false, err
+	}
+	defer db.Close()
+
+	return get(db, apiTokenKey)
+}
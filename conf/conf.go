@@ -9,15 +9,113 @@ const (
 	HeaderContentType             = "Content-Type"
 	HeaderContentDisposition      = "Content-Disposition"
 	HeaderContentTypeJSON         = "application/json"
+	HeaderArchiveJobID            = "X-Archive-Job-Id"
 	DefaultStorageFolder          = "timelapse-pictures"
 	TempFilesFolder               = "/tmp"
 	LogFile                       = "timelapse.log"
 	// Validation bounds
-	MinSecondsBetweenCaptures = 10  // Allow sufficient exposure time
-	MinQuality                = 1   // Quality must be at least 1
-	MaxQuality                = 100 // Quality cannot exceed 100
-	MinOffsetWithinHour       = 0   // Offset within hour minimum
+	MinSecondsBetweenCaptures = 10   // Allow sufficient exposure time
+	MinQuality                = 1    // Quality must be at least 1
+	MaxQuality                = 100  // Quality cannot exceed 100
+	MinOffsetWithinHour       = 0    // Offset within hour minimum
 	MaxOffsetWithinHour       = 3599 // Offset within hour maximum (59 minutes 59 seconds)
+	// Object detection defaults and bounds
+	DefaultDetectionTimeoutSeconds      = 30
+	DefaultDetectionInputSize           = 416
+	DefaultDetectionConfidenceThreshold = 0.5
+	DefaultDetectionNMSThreshold        = 0.4
+	MinDetectionInputSize               = 32
+	MaxDetectionInputSize               = 1280
+	MinDetectionThreshold               = 0.0
+	MaxDetectionThreshold               = 1.0
+	// Perceptual-hash detection cache defaults and bounds (see
+	// detection.CachingAnalyzer)
+	DefaultDetectionCacheCapacity         = 32
+	DefaultDetectionCacheHammingThreshold = 4
+	MinDetectionCacheCapacity             = 1
+	MaxDetectionCacheCapacity             = 1000
+	MinDetectionCacheHammingThreshold     = 0
+	MaxDetectionCacheHammingThreshold     = 64
+	// EXIF defaults
+	DefaultCameraMake  = "Raspberry Pi Foundation"
+	DefaultCameraModel = "Camera Module"
+	// Video archive defaults (see the video package)
+	DefaultVideoFps        = 24
+	DefaultVideoResolution = "1080p"
+	// Motion-triggered capture defaults and bounds (see detection.DetectMotion)
+	DefaultMotionGridCols           = 16
+	DefaultMotionGridRows           = 12
+	DefaultMotionCellDeltaThreshold = 15.0
+	DefaultMotionChangedCellRatio   = 0.05
+	DefaultMotionCooldownSeconds    = 30
+	MinMotionGridSize               = 1
+	MaxMotionGridSize               = 64
+	// ActivityPub/Fediverse publishing defaults (see the publish package)
+	DefaultPublishActorHandle = "timelapse"
+	DefaultPublishVisibility  = "public"
+	// Structured events backend defaults (see the events package)
+	DefaultEventsBackend        = "logfile"
+	DefaultEventsMemoryCapacity = 500
+	// Log cache defaults (see the logcache package)
+	DefaultLogCacheMaxLines = 1000
+	DefaultLogCacheMaxBytes = 1 << 20 // 1 MB
+	// Logging verbosity levels and default (see the log package)
+	DefaultVerbosity = 1
+	MinVerbosity     = 0
+	MaxVerbosity     = 3
+	// Parallel gzip (.tar.gz) archive defaults and bounds (see
+	// files.TarGzWithPipes and the archivegzip package)
+	DefaultArchiveCompressionLevel = -1      // gzip.DefaultCompression
+	DefaultArchiveGzipBlockSize    = 1 << 20 // 1 MB
+	DefaultArchiveGzipWorkers      = 4
+	MinArchiveCompressionLevel     = -1 // gzip.DefaultCompression
+	MaxArchiveCompressionLevel     = 9  // gzip.BestCompression
+	MinArchiveGzipBlockSize        = 64 * 1024
+	MaxArchiveGzipBlockSize        = 64 * 1024 * 1024
+	MinArchiveGzipWorkers          = 1
+	MaxArchiveGzipWorkers          = 64
+	// Shared capture pacer defaults and bounds (see timelapse.Capturer.Capture)
+	DefaultMinCaptureIntervalSeconds = 0 // no enforced minimum spacing
+	DefaultMaxBackoffSeconds         = 60
+	DefaultCaptureTimeoutSeconds     = 30
+	MinMinCaptureIntervalSeconds     = 0
+	MaxMinCaptureIntervalSeconds     = 3600
+	MinMaxBackoffSeconds             = 1
+	MaxMaxBackoffSeconds             = 3600
+	MinCaptureTimeoutSeconds         = 1
+	MaxCaptureTimeoutSeconds         = 600
+	// Capture backend default (see timelapse.NewCamera's Backend* constants)
+	DefaultCaptureBackend = "auto"
+	// rpicam-still/libcamera-still image-tuning bounds (see
+	// timelapse.StillTuning). These mirror the tools' own documented ranges;
+	// a zero-valued field means "unset", so a default Settings leaves the
+	// tools' built-in defaults untouched.
+	MinContrast       = 0.0
+	MaxContrast       = 15.99
+	MinSaturation     = 0.0
+	MaxSaturation     = 15.99
+	MinSharpness      = 0.0
+	MaxSharpness      = 15.99
+	MinBrightness     = -1.0
+	MaxBrightness     = 1.0
+	MinISO            = 100
+	MaxISO            = 3200
+	MinShutterSpeedUs = 0
+	MaxShutterSpeedUs = 200000000 // 200s, a practical long-exposure ceiling
+	MinEVCompensation = -10.0
+	MaxEVCompensation = 10.0
+	// HomeKit IP camera accessory defaults (see the hkcam package).
+	// DefaultHomeKitPIN matches brutella/hap's own example pairing code;
+	// operators who care about it being guessable should set their own via
+	// the settings API before exposing the accessory.
+	DefaultHomeKitBridgeName = "Timelapse Camera"
+	DefaultHomeKitPIN        = "00102003"
+	// Video render job watchdog defaults and bounds (see video.Renderer). A
+	// render exceeding this many seconds of wall-clock time is assumed stuck
+	// and its ffmpeg process is killed.
+	DefaultRenderMaxDurationSeconds = 1800 // 30min
+	MinRenderMaxDurationSeconds     = 60
+	MaxRenderMaxDurationSeconds     = 21600 // 6h
 )
 
 var (
@@ -28,7 +126,7 @@ var (
 )
 
 // OverrideDefaultConfig Override default config values which were provided.
-// Note: These global values are used for initial configuration only. 
+// Note: These global values are used for initial configuration only.
 // The Settings struct handles the actual runtime configuration with proper priority:
 // CLI flags → persisted settings → defaults (see CONFIGURATION.md for details)
 func OverrideDefaultConfig(listenAddressOverride *string, storageAddressOverride *string, logToFileOverride *bool, secondsBetweenCapturesOverride *int) {
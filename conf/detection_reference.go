@@ -0,0 +1,53 @@
+package conf
+
+import (
+	"encoding/json"
+
+	"github.com/boltdb/bolt"
+)
+
+const detectionReferenceKeyPrefix = "detection-reference:"
+
+// DetectionReference is a camera's last binarized change-detection frame
+// (see detection/sauvola.go), persisted so the comparison baseline survives
+// process restarts instead of resetting to "no prior frame" every boot.
+type DetectionReference struct {
+	Bitmap []byte `json:"bitmap"`
+}
+
+// StoreDetectionReference persists ref for camera, keyed separately from
+// Settings.JSON in the same settings bucket/file so it needs no bolt
+// database of its own.
+func StoreDetectionReference(camera string, ref DetectionReference) error {
+	db, err := bolt.Open(settingsFile, 0600, &bolt.Options{Timeout: boldIoTimeout})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	marshalled, err := json.Marshal(ref)
+	if err != nil {
+		return err
+	}
+	return set(db, detectionReferenceKeyPrefix+camera, marshalled)
+}
+
+// LoadDetectionReference returns the previously stored reference for
+// camera, if any.
+func LoadDetectionReference(camera string) (DetectionReference, bool, error) {
+	db, err := bolt.Open(settingsFile, 0600, &bolt.Options{Timeout: boldIoTimeout})
+	if err != nil {
+		return DetectionReference{}, false, err
+	}
+	defer db.Close()
+
+	val, exists, err := get(db, detectionReferenceKeyPrefix+camera)
+	if err != nil || !exists {
+		return DetectionReference{}, exists, err
+	}
+	var ref DetectionReference
+	if err := json.Unmarshal([]byte(val), &ref); err != nil {
+		return DetectionReference{}, false, err
+	}
+	return ref, true, nil
+}
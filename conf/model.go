@@ -15,17 +15,51 @@ var missingBucketError = errors.New("missing bucket")
 var settingsNotFound = errors.New("settings not found")
 
 var initialConfiguration = Settings{
-	DebugEnabled:           false,
+	Verbosity:              DefaultVerbosity,
 	SecondsBetweenCaptures: SecondsBetweenCaptures,
 	OffsetWithinHour:       DefaultOffsetWithinHour,
 	// Default resolution: 3280x2464 (8 MP). 66%: 2186x1642 (3.5 MP), 50%: 1640x1232 (2 MP)
-	PhotoResolutionWidth:    2186,
-	PhotoResolutionHeight:   1642,
-	PreviewResolutionWidth:  640,
-	PreviewResolutionHeight: 480,
-	RotateBy:                0,
-	ResolutionSetting:       0,
-	Quality:                 100,
+	PhotoResolutionWidth:           2186,
+	PhotoResolutionHeight:          1642,
+	PreviewResolutionWidth:         640,
+	PreviewResolutionHeight:        480,
+	RotateBy:                       0,
+	ResolutionSetting:              0,
+	Quality:                        100,
+	ObjectDetectionEnabled:         false,
+	UseOpenCVDetection:             true,
+	DetectionTimeout:               DefaultDetectionTimeoutSeconds,
+	DetectionInputSize:             DefaultDetectionInputSize,
+	DetectionConfidenceThreshold:   DefaultDetectionConfidenceThreshold,
+	DetectionNMSThreshold:          DefaultDetectionNMSThreshold,
+	DetectionCacheEnabled:          false,
+	DetectionCacheCapacity:         DefaultDetectionCacheCapacity,
+	DetectionCacheHammingThreshold: DefaultDetectionCacheHammingThreshold,
+	CameraMake:                     DefaultCameraMake,
+	CameraModel:                    DefaultCameraModel,
+	GPSEnabled:                     false,
+	MotionCaptureEnabled:           false,
+	MotionGridCols:                 DefaultMotionGridCols,
+	MotionGridRows:                 DefaultMotionGridRows,
+	MotionCellDeltaThreshold:       DefaultMotionCellDeltaThreshold,
+	MotionChangedCellRatio:         DefaultMotionChangedCellRatio,
+	MotionCooldownSeconds:          DefaultMotionCooldownSeconds,
+	PublishEnabled:                 false,
+	PublishActorHandle:             DefaultPublishActorHandle,
+	PublishVisibility:              DefaultPublishVisibility,
+	EventsBackend:                  DefaultEventsBackend,
+	EventsMemoryCapacity:           DefaultEventsMemoryCapacity,
+	ArchiveCompressionLevel:        DefaultArchiveCompressionLevel,
+	ArchiveGzipBlockSize:           DefaultArchiveGzipBlockSize,
+	ArchiveGzipWorkers:             DefaultArchiveGzipWorkers,
+	MinCaptureIntervalSeconds:      DefaultMinCaptureIntervalSeconds,
+	MaxBackoffSeconds:              DefaultMaxBackoffSeconds,
+	CaptureTimeoutSeconds:          DefaultCaptureTimeoutSeconds,
+	CaptureBackend:                 DefaultCaptureBackend,
+	HomeKitEnabled:                 false,
+	HomeKitBridgeName:              DefaultHomeKitBridgeName,
+	HomeKitPIN:                     DefaultHomeKitPIN,
+	RenderMaxDurationSeconds:       DefaultRenderMaxDurationSeconds,
 }
 
 type Settings struct {
@@ -38,7 +72,119 @@ type Settings struct {
 	RotateBy                int
 	ResolutionSetting       int
 	Quality                 int
-	DebugEnabled            bool
+	// Verbosity controls logging detail: 0=quiet, 1=info, 2=debug, 3=trace
+	// (see the log package).
+	Verbosity int
+
+	// Object detection (see the detection package)
+	ObjectDetectionEnabled bool
+	UseOpenCVDetection     bool
+	DetectionTimeout       int // seconds
+
+	// Native OpenCV model selection. DetectionModelPath may point at a Darknet
+	// .weights file (paired with DetectionModelConfigPath) or a YOLOv8 .onnx
+	// export; the extension picked at load time determines which is used.
+	DetectionModelPath           string
+	DetectionModelConfigPath     string
+	DetectionModelClassesPath    string
+	DetectionInputSize           int
+	DetectionConfidenceThreshold float64
+	DetectionNMSThreshold        float64
+
+	// Perceptual-hash result cache (see detection.CachingAnalyzer). When
+	// enabled, consecutive frames whose dHash is within
+	// DetectionCacheHammingThreshold bits of a recent frame reuse its
+	// DetectionResult instead of re-running analysis.
+	DetectionCacheEnabled          bool
+	DetectionCacheCapacity         int
+	DetectionCacheHammingThreshold int
+
+	// EXIF metadata embedded into each captured JPEG (see the exif package)
+	CameraMake   string
+	CameraModel  string
+	GPSEnabled   bool
+	GPSLatitude  float64
+	GPSLongitude float64
+
+	// Motion-triggered capture: only keep frames where integral-image,
+	// grid-based differencing against the last kept frame shows enough
+	// change (see detection.DetectMotion).
+	MotionCaptureEnabled     bool
+	MotionGridCols           int
+	MotionGridRows           int
+	MotionCellDeltaThreshold float64
+	MotionChangedCellRatio   float64
+	MotionCooldownSeconds    int
+
+	// ActivityPub/Fediverse publishing (see the publish package)
+	PublishEnabled                   bool
+	PublishDomain                    string
+	PublishActorHandle               string
+	PublishFollowedInstanceAllowlist string
+	PublishVisibility                string
+	PublishAttachDetectionSummary    bool
+	PublishPrivateKeyPEM             string
+	PublishFollowerInboxes           string
+
+	// Structured events backend (see the events package)
+	EventsBackend        string
+	EventsMemoryCapacity int
+
+	// Parallel gzip tuning for the /archive?format=tar.gz route (see
+	// files.TarGzWithPipes and the archivegzip package)
+	ArchiveCompressionLevel int
+	ArchiveGzipBlockSize    int
+	ArchiveGzipWorkers      int
+
+	// Shared capture pacer tuning (see timelapse.Capturer.Capture), applied to
+	// every camera invocation regardless of whether it came from the
+	// scheduled timelapse loop or a REST-triggered preview
+	MinCaptureIntervalSeconds int
+	MaxBackoffSeconds         int
+	CaptureTimeoutSeconds     int
+
+	// AllowedOrigins is a comma-separated CORS allowlist for rest.NewAPI
+	// (see rest.APIOptions). Empty allows any origin, matching this server's
+	// historical Access-Control-Allow-Origin: * behavior.
+	AllowedOrigins string
+
+	// CaptureBackend selects which timelapse.Capturer drives the camera (see
+	// timelapse.NewCamera's Backend* constants). Empty/"auto" preserves this
+	// project's original architecture-based selection.
+	CaptureBackend string
+	// RTSPURL is the stream URL used when CaptureBackend is "rtsp".
+	RTSPURL string
+
+	// rpicam-still/libcamera-still image tuning (see timelapse.StillTuning),
+	// for astro/night timelapses that need finer exposure control than
+	// width/height/quality alone. Applies only to the rpicam/libcamera
+	// backends; ignored by ffmpeg-v4l2 and rtsp. Zero/empty values leave the
+	// tool's own default in place.
+	Contrast       float64
+	Saturation     float64
+	Sharpness      float64
+	Brightness     float64
+	ExposureMode   string // e.g. "auto", "night", "sports"
+	AWBMode        string
+	ISO            int
+	ShutterSpeedUs int
+	EVCompensation float64
+	Denoise        string // e.g. "off", "cdn_fast", "cdn_hq"
+	// TextOverlay is rendered onto each photo via --annotate, which supports
+	// strftime tokens (e.g. "%Y-%m-%d %H:%M:%S") natively.
+	TextOverlay string
+
+	// HomeKit IP camera accessory (see the hkcam package), built against
+	// github.com/brutella/hap behind the "homekit" build tag. Binaries built
+	// without that tag ignore HomeKitEnabled entirely.
+	HomeKitEnabled    bool
+	HomeKitBridgeName string
+	HomeKitPIN        string
+
+	// RenderMaxDurationSeconds bounds how long a single /video/render job's
+	// ffmpeg process may run before the watchdog kills it as stuck (see
+	// video.Renderer). 0 falls back to DefaultRenderMaxDurationSeconds.
+	RenderMaxDurationSeconds int
 }
 
 func (s Settings) String() string {
@@ -75,5 +221,193 @@ func (s Settings) Sanitize() Settings {
 		}
 	}
 
+	// Enforce detection input size and threshold bounds
+	if sanitized.DetectionInputSize != 0 {
+		if sanitized.DetectionInputSize < MinDetectionInputSize {
+			sanitized.DetectionInputSize = MinDetectionInputSize
+		}
+		if sanitized.DetectionInputSize > MaxDetectionInputSize {
+			sanitized.DetectionInputSize = MaxDetectionInputSize
+		}
+	}
+	if sanitized.DetectionConfidenceThreshold < MinDetectionThreshold || sanitized.DetectionConfidenceThreshold > MaxDetectionThreshold {
+		sanitized.DetectionConfidenceThreshold = DefaultDetectionConfidenceThreshold
+	}
+	if sanitized.DetectionNMSThreshold < MinDetectionThreshold || sanitized.DetectionNMSThreshold > MaxDetectionThreshold {
+		sanitized.DetectionNMSThreshold = DefaultDetectionNMSThreshold
+	}
+
+	// Enforce detection cache capacity and Hamming threshold bounds
+	if sanitized.DetectionCacheCapacity != 0 {
+		if sanitized.DetectionCacheCapacity < MinDetectionCacheCapacity {
+			sanitized.DetectionCacheCapacity = MinDetectionCacheCapacity
+		}
+		if sanitized.DetectionCacheCapacity > MaxDetectionCacheCapacity {
+			sanitized.DetectionCacheCapacity = MaxDetectionCacheCapacity
+		}
+	}
+	if sanitized.DetectionCacheHammingThreshold < MinDetectionCacheHammingThreshold || sanitized.DetectionCacheHammingThreshold > MaxDetectionCacheHammingThreshold {
+		sanitized.DetectionCacheHammingThreshold = DefaultDetectionCacheHammingThreshold
+	}
+
+	// Enforce motion-detection grid size and changed-cell ratio bounds
+	if sanitized.MotionGridCols != 0 {
+		if sanitized.MotionGridCols < MinMotionGridSize {
+			sanitized.MotionGridCols = MinMotionGridSize
+		}
+		if sanitized.MotionGridCols > MaxMotionGridSize {
+			sanitized.MotionGridCols = MaxMotionGridSize
+		}
+	}
+	if sanitized.MotionGridRows != 0 {
+		if sanitized.MotionGridRows < MinMotionGridSize {
+			sanitized.MotionGridRows = MinMotionGridSize
+		}
+		if sanitized.MotionGridRows > MaxMotionGridSize {
+			sanitized.MotionGridRows = MaxMotionGridSize
+		}
+	}
+	if sanitized.MotionChangedCellRatio < 0 || sanitized.MotionChangedCellRatio > 1 {
+		sanitized.MotionChangedCellRatio = DefaultMotionChangedCellRatio
+	}
+	if sanitized.MotionCooldownSeconds < 0 {
+		sanitized.MotionCooldownSeconds = DefaultMotionCooldownSeconds
+	}
+
+	// Enforce a known post visibility
+	if sanitized.PublishVisibility != "public" && sanitized.PublishVisibility != "unlisted" {
+		sanitized.PublishVisibility = DefaultPublishVisibility
+	}
+
+	// Enforce a known logging verbosity level
+	if sanitized.Verbosity < MinVerbosity || sanitized.Verbosity > MaxVerbosity {
+		sanitized.Verbosity = DefaultVerbosity
+	}
+
+	// Enforce a known events backend and a sane memory ring capacity
+	if sanitized.EventsBackend != "logfile" && sanitized.EventsBackend != "memory" && sanitized.EventsBackend != "null" {
+		sanitized.EventsBackend = DefaultEventsBackend
+	}
+	if sanitized.EventsMemoryCapacity <= 0 {
+		sanitized.EventsMemoryCapacity = DefaultEventsMemoryCapacity
+	}
+
+	// Enforce parallel gzip archive tuning bounds
+	if sanitized.ArchiveCompressionLevel < MinArchiveCompressionLevel || sanitized.ArchiveCompressionLevel > MaxArchiveCompressionLevel {
+		sanitized.ArchiveCompressionLevel = DefaultArchiveCompressionLevel
+	}
+	if sanitized.ArchiveGzipBlockSize != 0 {
+		if sanitized.ArchiveGzipBlockSize < MinArchiveGzipBlockSize {
+			sanitized.ArchiveGzipBlockSize = MinArchiveGzipBlockSize
+		}
+		if sanitized.ArchiveGzipBlockSize > MaxArchiveGzipBlockSize {
+			sanitized.ArchiveGzipBlockSize = MaxArchiveGzipBlockSize
+		}
+	}
+	if sanitized.ArchiveGzipWorkers != 0 {
+		if sanitized.ArchiveGzipWorkers < MinArchiveGzipWorkers {
+			sanitized.ArchiveGzipWorkers = MinArchiveGzipWorkers
+		}
+		if sanitized.ArchiveGzipWorkers > MaxArchiveGzipWorkers {
+			sanitized.ArchiveGzipWorkers = MaxArchiveGzipWorkers
+		}
+	}
+
+	// Enforce shared capture pacer tuning bounds
+	if sanitized.MinCaptureIntervalSeconds != 0 {
+		if sanitized.MinCaptureIntervalSeconds < MinMinCaptureIntervalSeconds || sanitized.MinCaptureIntervalSeconds > MaxMinCaptureIntervalSeconds {
+			sanitized.MinCaptureIntervalSeconds = DefaultMinCaptureIntervalSeconds
+		}
+	}
+	if sanitized.MaxBackoffSeconds != 0 {
+		if sanitized.MaxBackoffSeconds < MinMaxBackoffSeconds || sanitized.MaxBackoffSeconds > MaxMaxBackoffSeconds {
+			sanitized.MaxBackoffSeconds = DefaultMaxBackoffSeconds
+		}
+	}
+	if sanitized.CaptureTimeoutSeconds != 0 {
+		if sanitized.CaptureTimeoutSeconds < MinCaptureTimeoutSeconds || sanitized.CaptureTimeoutSeconds > MaxCaptureTimeoutSeconds {
+			sanitized.CaptureTimeoutSeconds = DefaultCaptureTimeoutSeconds
+		}
+	}
+
+	// Enforce the video render watchdog budget (see video.Renderer)
+	if sanitized.RenderMaxDurationSeconds != 0 {
+		if sanitized.RenderMaxDurationSeconds < MinRenderMaxDurationSeconds || sanitized.RenderMaxDurationSeconds > MaxRenderMaxDurationSeconds {
+			sanitized.RenderMaxDurationSeconds = DefaultRenderMaxDurationSeconds
+		}
+	}
+
+	// Enforce rpicam-still/libcamera-still image-tuning bounds (see
+	// timelapse.StillTuning); 0 means "unset" and is left alone.
+	if sanitized.Contrast != 0 {
+		if sanitized.Contrast < MinContrast {
+			sanitized.Contrast = MinContrast
+		}
+		if sanitized.Contrast > MaxContrast {
+			sanitized.Contrast = MaxContrast
+		}
+	}
+	if sanitized.Saturation != 0 {
+		if sanitized.Saturation < MinSaturation {
+			sanitized.Saturation = MinSaturation
+		}
+		if sanitized.Saturation > MaxSaturation {
+			sanitized.Saturation = MaxSaturation
+		}
+	}
+	if sanitized.Sharpness != 0 {
+		if sanitized.Sharpness < MinSharpness {
+			sanitized.Sharpness = MinSharpness
+		}
+		if sanitized.Sharpness > MaxSharpness {
+			sanitized.Sharpness = MaxSharpness
+		}
+	}
+	if sanitized.Brightness != 0 {
+		if sanitized.Brightness < MinBrightness {
+			sanitized.Brightness = MinBrightness
+		}
+		if sanitized.Brightness > MaxBrightness {
+			sanitized.Brightness = MaxBrightness
+		}
+	}
+	if sanitized.ISO != 0 {
+		if sanitized.ISO < MinISO {
+			sanitized.ISO = MinISO
+		}
+		if sanitized.ISO > MaxISO {
+			sanitized.ISO = MaxISO
+		}
+	}
+	if sanitized.ShutterSpeedUs != 0 {
+		if sanitized.ShutterSpeedUs < MinShutterSpeedUs {
+			sanitized.ShutterSpeedUs = MinShutterSpeedUs
+		}
+		if sanitized.ShutterSpeedUs > MaxShutterSpeedUs {
+			sanitized.ShutterSpeedUs = MaxShutterSpeedUs
+		}
+	}
+	if sanitized.EVCompensation != 0 {
+		if sanitized.EVCompensation < MinEVCompensation {
+			sanitized.EVCompensation = MinEVCompensation
+		}
+		if sanitized.EVCompensation > MaxEVCompensation {
+			sanitized.EVCompensation = MaxEVCompensation
+		}
+	}
+
 	return sanitized
 }
+
+// ApplyCLIOverrides returns s with any explicitly-provided CLI flag values
+// applied on top, giving CLI flags priority over persisted settings.
+func (s Settings) ApplyCLIOverrides(secondsBetweenCaptures *int, verbosity *int) Settings {
+	updated := s
+	if secondsBetweenCaptures != nil {
+		updated.SecondsBetweenCaptures = *secondsBetweenCaptures
+	}
+	if verbosity != nil {
+		updated.Verbosity = *verbosity
+	}
+	return updated
+}
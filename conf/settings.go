@@ -15,6 +15,26 @@ const (
 )
 
 func LoadConfiguration() (*Settings, error) {
+	existingSettings, err := loadConfigurationRaw()
+	if err != nil {
+		return existingSettings, err
+	}
+
+	if existingSettings.SecondsBetweenCaptures < minSecondsBetweenCaptures {
+		// Enforce min time between captures. this also protects for errors as a result of this being 0.
+		existingSettings.SecondsBetweenCaptures = minSecondsBetweenCaptures
+	}
+
+	return existingSettings, err
+}
+
+// loadConfigurationRaw loads settings from disk exactly as persisted,
+// applying only the Verbosity/DebugEnabled backward-compatibility mapping,
+// not LoadConfiguration's further SecondsBetweenCaptures clamping. Watcher
+// uses this so it can tell a genuinely invalid on-disk value, which it
+// should refuse to publish, apart from one LoadConfiguration would already
+// have silently corrected.
+func loadConfigurationRaw() (*Settings, error) {
 	if areSettingsMissing(settingsFile) {
 		log.Println("Creating initial settings file..")
 		return WriteConfiguration(initialConfiguration)
@@ -36,9 +56,16 @@ func LoadConfiguration() (*Settings, error) {
 	var existingSettings Settings
 	err = json.Unmarshal([]byte(val), &existingSettings)
 
-	if existingSettings.SecondsBetweenCaptures < minSecondsBetweenCaptures {
-		// Enforce min time between captures. this also protects for errors as a result of this being 0.
-		existingSettings.SecondsBetweenCaptures = minSecondsBetweenCaptures
+	// Backward compatibility: settings persisted before Verbosity replaced the
+	// boolean DebugEnabled flag carry no Verbosity field, so map a prior
+	// DebugEnabled=true to the equivalent Verbosity=2 (debug).
+	if existingSettings.Verbosity == 0 {
+		var legacy struct {
+			DebugEnabled bool
+		}
+		if json.Unmarshal([]byte(val), &legacy) == nil && legacy.DebugEnabled {
+			existingSettings.Verbosity = 2
+		}
 	}
 
 	return &existingSettings, err
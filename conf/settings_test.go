@@ -12,7 +12,7 @@ func TestAreSettingsMissing(t *testing.T) {
 }
 
 func TestSettingsToString(t *testing.T) {
-	expected := `{"SecondsBetweenCaptures":0,"OffsetWithinHour":0,"PhotoResolutionWidth":0,"PhotoResolutionHeight":0,"PreviewResolutionWidth":0,"PreviewResolutionHeight":0,"RotateBy":0,"ResolutionSetting":0,"Quality":0,"DebugEnabled":false}`
+	expected := `{"SecondsBetweenCaptures":0,"OffsetWithinHour":0,"PhotoResolutionWidth":0,"PhotoResolutionHeight":0,"PreviewResolutionWidth":0,"PreviewResolutionHeight":0,"RotateBy":0,"ResolutionSetting":0,"Quality":0,"Verbosity":0,"ObjectDetectionEnabled":false,"UseOpenCVDetection":false,"DetectionTimeout":0,"DetectionModelPath":"","DetectionModelConfigPath":"","DetectionModelClassesPath":"","DetectionInputSize":0,"DetectionConfidenceThreshold":0,"DetectionNMSThreshold":0,"DetectionCacheEnabled":false,"DetectionCacheCapacity":0,"DetectionCacheHammingThreshold":0,"CameraMake":"","CameraModel":"","GPSEnabled":false,"GPSLatitude":0,"GPSLongitude":0,"MotionCaptureEnabled":false,"MotionGridCols":0,"MotionGridRows":0,"MotionCellDeltaThreshold":0,"MotionChangedCellRatio":0,"MotionCooldownSeconds":0,"PublishEnabled":false,"PublishDomain":"","PublishActorHandle":"","PublishFollowedInstanceAllowlist":"","PublishVisibility":"","PublishAttachDetectionSummary":false,"PublishPrivateKeyPEM":"","PublishFollowerInboxes":"","EventsBackend":"","EventsMemoryCapacity":0,"ArchiveCompressionLevel":0,"ArchiveGzipBlockSize":0,"ArchiveGzipWorkers":0,"MinCaptureIntervalSeconds":0,"MaxBackoffSeconds":0,"CaptureTimeoutSeconds":0,"AllowedOrigins":"","CaptureBackend":"","RTSPURL":"","Contrast":0,"Saturation":0,"Sharpness":0,"Brightness":0,"ExposureMode":"","AWBMode":"","ISO":0,"ShutterSpeedUs":0,"EVCompensation":0,"Denoise":"","TextOverlay":"","HomeKitEnabled":false,"HomeKitBridgeName":"","HomeKitPIN":"","RenderMaxDurationSeconds":0}`
 	ensure.DeepEqual(t, expected, Settings{}.String())
 }
 
@@ -60,6 +60,36 @@ func TestSanitizeSecondsBetweenCaptures(t *testing.T) {
 	ensure.DeepEqual(t, 1800, sanitized.SecondsBetweenCaptures)
 }
 
+func TestSanitizeVerbosity(t *testing.T) {
+	// Test negative verbosity gets sanitized to the default
+	s := Settings{Verbosity: -1}
+	sanitized := s.Sanitize()
+	ensure.DeepEqual(t, DefaultVerbosity, sanitized.Verbosity)
+
+	// Test verbosity above the maximum gets sanitized to the default
+	s = Settings{Verbosity: MaxVerbosity + 1}
+	sanitized = s.Sanitize()
+	ensure.DeepEqual(t, DefaultVerbosity, sanitized.Verbosity)
+
+	// Test valid verbosity is unchanged
+	s = Settings{Verbosity: MaxVerbosity}
+	sanitized = s.Sanitize()
+	ensure.DeepEqual(t, MaxVerbosity, sanitized.Verbosity)
+}
+
+func TestApplyCLIOverrides(t *testing.T) {
+	s := Settings{SecondsBetweenCaptures: 1800, Verbosity: 1}
+
+	unchanged := s.ApplyCLIOverrides(nil, nil)
+	ensure.DeepEqual(t, s, unchanged)
+
+	seconds := 60
+	verbosity := 3
+	overridden := s.ApplyCLIOverrides(&seconds, &verbosity)
+	ensure.DeepEqual(t, 60, overridden.SecondsBetweenCaptures)
+	ensure.DeepEqual(t, 3, overridden.Verbosity)
+}
+
 func TestSanitizeOffsetWithinHour(t *testing.T) {
 	// Test -1 (disabled) is allowed and unchanged
 	s := Settings{OffsetWithinHour: -1}
@@ -16,5 +16,95 @@ func (s strictValidator) Validate(settings conf.Settings) error {
 			return errOffsetWithinHourOutOfBounds
 		}
 	}
+	if settings.DetectionInputSize != 0 {
+		if settings.DetectionInputSize < conf.MinDetectionInputSize || settings.DetectionInputSize > conf.MaxDetectionInputSize {
+			return errDetectionInputSizeOutOfBounds
+		}
+	}
+	if settings.DetectionConfidenceThreshold < conf.MinDetectionThreshold || settings.DetectionConfidenceThreshold > conf.MaxDetectionThreshold {
+		return errDetectionThresholdOutOfBounds
+	}
+	if settings.DetectionNMSThreshold < conf.MinDetectionThreshold || settings.DetectionNMSThreshold > conf.MaxDetectionThreshold {
+		return errDetectionThresholdOutOfBounds
+	}
+	if settings.DetectionCacheCapacity != 0 {
+		if settings.DetectionCacheCapacity < conf.MinDetectionCacheCapacity || settings.DetectionCacheCapacity > conf.MaxDetectionCacheCapacity {
+			return errDetectionCacheOutOfBounds
+		}
+	}
+	if settings.DetectionCacheHammingThreshold < conf.MinDetectionCacheHammingThreshold || settings.DetectionCacheHammingThreshold > conf.MaxDetectionCacheHammingThreshold {
+		return errDetectionCacheOutOfBounds
+	}
+	if settings.Verbosity < conf.MinVerbosity || settings.Verbosity > conf.MaxVerbosity {
+		return errVerbosityOutOfBounds
+	}
+	if settings.ArchiveCompressionLevel < conf.MinArchiveCompressionLevel || settings.ArchiveCompressionLevel > conf.MaxArchiveCompressionLevel {
+		return errArchiveGzipOutOfBounds
+	}
+	if settings.ArchiveGzipBlockSize != 0 {
+		if settings.ArchiveGzipBlockSize < conf.MinArchiveGzipBlockSize || settings.ArchiveGzipBlockSize > conf.MaxArchiveGzipBlockSize {
+			return errArchiveGzipOutOfBounds
+		}
+	}
+	if settings.ArchiveGzipWorkers != 0 {
+		if settings.ArchiveGzipWorkers < conf.MinArchiveGzipWorkers || settings.ArchiveGzipWorkers > conf.MaxArchiveGzipWorkers {
+			return errArchiveGzipOutOfBounds
+		}
+	}
+	if settings.MinCaptureIntervalSeconds != 0 {
+		if settings.MinCaptureIntervalSeconds < conf.MinMinCaptureIntervalSeconds || settings.MinCaptureIntervalSeconds > conf.MaxMinCaptureIntervalSeconds {
+			return errCapturePacerOutOfBounds
+		}
+	}
+	if settings.MaxBackoffSeconds != 0 {
+		if settings.MaxBackoffSeconds < conf.MinMaxBackoffSeconds || settings.MaxBackoffSeconds > conf.MaxMaxBackoffSeconds {
+			return errCapturePacerOutOfBounds
+		}
+	}
+	if settings.CaptureTimeoutSeconds != 0 {
+		if settings.CaptureTimeoutSeconds < conf.MinCaptureTimeoutSeconds || settings.CaptureTimeoutSeconds > conf.MaxCaptureTimeoutSeconds {
+			return errCapturePacerOutOfBounds
+		}
+	}
+	if settings.Contrast != 0 {
+		if settings.Contrast < conf.MinContrast || settings.Contrast > conf.MaxContrast {
+			return errStillTuningOutOfBounds
+		}
+	}
+	if settings.Saturation != 0 {
+		if settings.Saturation < conf.MinSaturation || settings.Saturation > conf.MaxSaturation {
+			return errStillTuningOutOfBounds
+		}
+	}
+	if settings.Sharpness != 0 {
+		if settings.Sharpness < conf.MinSharpness || settings.Sharpness > conf.MaxSharpness {
+			return errStillTuningOutOfBounds
+		}
+	}
+	if settings.Brightness != 0 {
+		if settings.Brightness < conf.MinBrightness || settings.Brightness > conf.MaxBrightness {
+			return errStillTuningOutOfBounds
+		}
+	}
+	if settings.ISO != 0 {
+		if settings.ISO < conf.MinISO || settings.ISO > conf.MaxISO {
+			return errStillTuningOutOfBounds
+		}
+	}
+	if settings.ShutterSpeedUs != 0 {
+		if settings.ShutterSpeedUs < conf.MinShutterSpeedUs || settings.ShutterSpeedUs > conf.MaxShutterSpeedUs {
+			return errStillTuningOutOfBounds
+		}
+	}
+	if settings.EVCompensation != 0 {
+		if settings.EVCompensation < conf.MinEVCompensation || settings.EVCompensation > conf.MaxEVCompensation {
+			return errStillTuningOutOfBounds
+		}
+	}
+	if settings.RenderMaxDurationSeconds != 0 {
+		if settings.RenderMaxDurationSeconds < conf.MinRenderMaxDurationSeconds || settings.RenderMaxDurationSeconds > conf.MaxRenderMaxDurationSeconds {
+			return errRenderMaxDurationOutOfBounds
+		}
+	}
 	return nil
 }
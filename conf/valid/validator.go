@@ -10,6 +10,14 @@ var (
 	errQualityOutOfBounds                = errors.New("Quality is out of bounds")
 	errOffsetWithinHourOutOfBounds       = errors.New("Offset within hour is out of bounds")
 	errSecondsBetweenCapturesOutOfBounds = errors.New("Seconds between captures is out of bounds")
+	errDetectionInputSizeOutOfBounds     = errors.New("Detection input size is out of bounds")
+	errDetectionThresholdOutOfBounds     = errors.New("Detection confidence/NMS threshold is out of bounds")
+	errDetectionCacheOutOfBounds         = errors.New("Detection cache capacity/Hamming threshold is out of bounds")
+	errVerbosityOutOfBounds              = errors.New("Verbosity is out of bounds")
+	errArchiveGzipOutOfBounds            = errors.New("Archive gzip compression level/block size/worker count is out of bounds")
+	errCapturePacerOutOfBounds           = errors.New("Capture pacer min interval/max backoff/timeout is out of bounds")
+	errStillTuningOutOfBounds            = errors.New("Contrast/saturation/sharpness/brightness/ISO/shutter speed/EV compensation is out of bounds")
+	errRenderMaxDurationOutOfBounds      = errors.New("Render max duration is out of bounds")
 )
 
 type Validator interface {
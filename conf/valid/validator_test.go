@@ -22,3 +22,102 @@ func TestOutOfBounds(t *testing.T) {
 	ensure.NotNil(t, New().Validate(conf.Settings{Quality: 100, SecondsBetweenCaptures: conf.MinSecondsBetweenCaptures - 1, OffsetWithinHour: 0}))
 	ensure.NotNil(t, New().Validate(conf.Settings{Quality: conf.MinQuality - 1, SecondsBetweenCaptures: conf.MinSecondsBetweenCaptures, OffsetWithinHour: 0}))
 }
+
+func TestVerbosityOutOfBounds(t *testing.T) {
+	base := conf.Settings{Quality: 100, SecondsBetweenCaptures: 60, OffsetWithinHour: 0}
+
+	base.Verbosity = -1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.Verbosity = conf.MaxVerbosity + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.Verbosity = conf.MaxVerbosity
+	ensure.Nil(t, New().Validate(base))
+}
+
+func TestArchiveGzipOutOfBounds(t *testing.T) {
+	base := conf.Settings{Quality: 100, SecondsBetweenCaptures: 60, OffsetWithinHour: 0}
+
+	base.ArchiveCompressionLevel = conf.MinArchiveCompressionLevel - 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.ArchiveCompressionLevel = conf.MaxArchiveCompressionLevel + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.ArchiveCompressionLevel = conf.MaxArchiveCompressionLevel
+	base.ArchiveGzipBlockSize = conf.MinArchiveGzipBlockSize - 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.ArchiveGzipBlockSize = conf.DefaultArchiveGzipBlockSize
+	base.ArchiveGzipWorkers = conf.MaxArchiveGzipWorkers + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.ArchiveGzipWorkers = conf.DefaultArchiveGzipWorkers
+	ensure.Nil(t, New().Validate(base))
+}
+
+func TestCapturePacerOutOfBounds(t *testing.T) {
+	base := conf.Settings{Quality: 100, SecondsBetweenCaptures: 60, OffsetWithinHour: 0}
+
+	base.MinCaptureIntervalSeconds = conf.MaxMinCaptureIntervalSeconds + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.MinCaptureIntervalSeconds = conf.DefaultMinCaptureIntervalSeconds
+	base.MaxBackoffSeconds = conf.MaxMaxBackoffSeconds + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.MaxBackoffSeconds = conf.DefaultMaxBackoffSeconds
+	base.CaptureTimeoutSeconds = conf.MaxCaptureTimeoutSeconds + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.CaptureTimeoutSeconds = conf.DefaultCaptureTimeoutSeconds
+	ensure.Nil(t, New().Validate(base))
+}
+
+func TestStillTuningOutOfBounds(t *testing.T) {
+	base := conf.Settings{Quality: 100, SecondsBetweenCaptures: 60, OffsetWithinHour: 0}
+
+	base.Contrast = conf.MaxContrast + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.Contrast = 0
+	base.Saturation = conf.MaxSaturation + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.Saturation = 0
+	base.Sharpness = conf.MaxSharpness + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.Sharpness = 0
+	base.Brightness = conf.MaxBrightness + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.Brightness = 0
+	base.ISO = conf.MaxISO + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.ISO = 0
+	base.ShutterSpeedUs = conf.MaxShutterSpeedUs + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.ShutterSpeedUs = 0
+	base.EVCompensation = conf.MaxEVCompensation + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.EVCompensation = 0
+	ensure.Nil(t, New().Validate(base))
+}
+
+func TestRenderMaxDurationOutOfBounds(t *testing.T) {
+	base := conf.Settings{Quality: 100, SecondsBetweenCaptures: 60, OffsetWithinHour: 0}
+
+	base.RenderMaxDurationSeconds = conf.MaxRenderMaxDurationSeconds + 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.RenderMaxDurationSeconds = conf.MinRenderMaxDurationSeconds - 1
+	ensure.NotNil(t, New().Validate(base))
+
+	base.RenderMaxDurationSeconds = conf.DefaultRenderMaxDurationSeconds
+	ensure.Nil(t, New().Validate(base))
+}
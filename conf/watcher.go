@@ -0,0 +1,110 @@
+package conf
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// watcherPollInterval governs how often Watcher checks settingsFile's
+// modification time. fsnotify isn't available as a dependency in this
+// build, so Watcher polls the mtime instead; callers observe the same
+// debounced, sanitized updates either way.
+const watcherPollInterval = 250 * time.Millisecond
+
+// watcherDebounce is how long settingsFile must go unchanged before Watcher
+// reloads and publishes it, coalescing rapid successive writes (e.g. a
+// config editor that saves more than once) into a single update.
+const watcherDebounce = time.Second
+
+// Watcher polls settingsFile on disk and pushes freshly sanitized Settings
+// onto updates whenever it changes, so operators editing it directly (or a
+// REST write that bypasses this process, e.g. from another instance
+// pointed at the same file) take effect without a restart.
+type Watcher struct {
+	updates chan<- Settings
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewWatcher starts watching settingsFile in the background and publishing
+// changes to updates. Call Close to stop it.
+func NewWatcher(updates chan<- Settings) *Watcher {
+	w := &Watcher{
+		updates: updates,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Close stops the watcher's goroutine and waits for it to exit.
+func (w *Watcher) Close() {
+	close(w.stop)
+	<-w.done
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	lastModTime := w.modTime()
+	var lastChangeAt time.Time
+	published := true
+
+	ticker := time.NewTicker(watcherPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			modTime := w.modTime()
+			if modTime.IsZero() {
+				continue
+			}
+			if !modTime.Equal(lastModTime) {
+				lastModTime = modTime
+				lastChangeAt = time.Now()
+				published = false
+				continue
+			}
+			if !published && time.Since(lastChangeAt) >= watcherDebounce {
+				w.publish()
+				published = true
+			}
+		}
+	}
+}
+
+func (w *Watcher) modTime() time.Time {
+	info, err := os.Stat(settingsFile)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func (w *Watcher) publish() {
+	// Use loadConfigurationRaw, not LoadConfiguration: the latter already
+	// clamps SecondsBetweenCaptures up to its own legacy minimum, which
+	// would make the check below unreachable.
+	settings, err := loadConfigurationRaw()
+	if err != nil {
+		log.Printf("Watcher: failed to reload %s after change: %s\n", settingsFile, err.Error())
+		return
+	}
+
+	// Check the raw value on disk, before Sanitize clamps it up to
+	// MinSecondsBetweenCaptures: an operator who wrote an invalid value
+	// should see it refused, not silently rewritten.
+	if settings.SecondsBetweenCaptures < MinSecondsBetweenCaptures {
+		log.Printf("Watcher: refusing to publish %s, SecondsBetweenCaptures %d is below the minimum of %d\n", settingsFile, settings.SecondsBetweenCaptures, MinSecondsBetweenCaptures)
+		return
+	}
+
+	sanitized := settings.Sanitize()
+	log.Printf("Watcher: %s changed, publishing new configuration: %s\n", settingsFile, sanitized)
+	w.updates <- sanitized
+}
@@ -0,0 +1,97 @@
+package conf
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/facebookgo/ensure"
+)
+
+// waitForUpdate waits up to timeout for a Settings to arrive on updates,
+// failing the test if none does.
+func waitForUpdate(t *testing.T, updates <-chan Settings, timeout time.Duration) Settings {
+	t.Helper()
+	select {
+	case s := <-updates:
+		return s
+	case <-time.After(timeout):
+		t.Fatal("timed out waiting for watcher to publish an update")
+		return Settings{}
+	}
+}
+
+// assertNoUpdate fails the test if a Settings arrives on updates before
+// timeout elapses.
+func assertNoUpdate(t *testing.T, updates <-chan Settings, timeout time.Duration) {
+	t.Helper()
+	select {
+	case s := <-updates:
+		t.Fatalf("expected no update to be published, got: %s", s)
+	case <-time.After(timeout):
+	}
+}
+
+func TestWatcherPublishesSanitizedSettingsOnChange(t *testing.T) {
+	defer os.Remove(settingsFile)
+
+	_, err := WriteConfiguration(Settings{SecondsBetweenCaptures: 1800, Quality: 100})
+	ensure.Nil(t, err)
+
+	updates := make(chan Settings, 1)
+	w := NewWatcher(updates)
+	defer w.Close()
+
+	time.Sleep(2 * watcherPollInterval)
+	_, err = WriteConfiguration(Settings{SecondsBetweenCaptures: 42, Quality: 200})
+	ensure.Nil(t, err)
+
+	got := waitForUpdate(t, updates, watcherDebounce+5*watcherPollInterval)
+	ensure.DeepEqual(t, 42, got.SecondsBetweenCaptures)
+	ensure.DeepEqual(t, MaxQuality, got.Quality) // Sanitize clamps Quality, not SecondsBetweenCaptures here
+}
+
+func TestWatcherRefusesToPublishSecondsBetweenCapturesBelowMinimum(t *testing.T) {
+	defer os.Remove(settingsFile)
+
+	_, err := WriteConfiguration(Settings{SecondsBetweenCaptures: 1800, Quality: 100})
+	ensure.Nil(t, err)
+
+	updates := make(chan Settings, 1)
+	w := NewWatcher(updates)
+	defer w.Close()
+
+	time.Sleep(2 * watcherPollInterval)
+	_, err = WriteConfiguration(Settings{SecondsBetweenCaptures: 1, Quality: 100})
+	ensure.Nil(t, err)
+
+	assertNoUpdate(t, updates, watcherDebounce+5*watcherPollInterval)
+}
+
+func TestWatcherDebouncesRapidSuccessiveWrites(t *testing.T) {
+	defer os.Remove(settingsFile)
+
+	_, err := WriteConfiguration(Settings{SecondsBetweenCaptures: 1800, Quality: 100})
+	ensure.Nil(t, err)
+
+	updates := make(chan Settings, 2)
+	w := NewWatcher(updates)
+	defer w.Close()
+
+	time.Sleep(2 * watcherPollInterval)
+	for i := 0; i < 3; i++ {
+		_, err = WriteConfiguration(Settings{SecondsBetweenCaptures: 60 + i, Quality: 100})
+		ensure.Nil(t, err)
+		time.Sleep(watcherPollInterval)
+	}
+
+	got := waitForUpdate(t, updates, watcherDebounce+5*watcherPollInterval)
+	ensure.DeepEqual(t, 62, got.SecondsBetweenCaptures)
+
+	// The rapid writes coalesce into a single publish, not one per write.
+	select {
+	case s := <-updates:
+		t.Fatalf("expected rapid successive writes to coalesce into one publish, got a second: %s", s)
+	case <-time.After(watcherDebounce):
+	}
+}
@@ -0,0 +1,201 @@
+package detection
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image"
+	"math/bits"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/preprocess"
+)
+
+// CachingAnalyzer wraps AnalyzePhotoWithConfig with an in-memory,
+// perceptual-hash-keyed cache, so near-identical consecutive frames (a
+// static scene overnight, for example) are served a recent DetectionResult
+// instead of re-running a full analysis (and, when UseOpenCV is set,
+// spawning another Python subprocess) on every call.
+//
+// There's no vendored copy of hashicorp/golang-lru available in this
+// environment, so the cache below is a small hand-rolled, mutex-guarded LRU
+// list sized for the handful of entries a single Pi needs, rather than a
+// pulled-in dependency.
+type CachingAnalyzer struct {
+	mu        sync.Mutex
+	capacity  int
+	threshold int // max Hamming distance between dHashes to count as a near-hit
+	entries   []cacheEntry
+
+	hits     int
+	nearHits int
+	misses   int
+}
+
+type cacheEntry struct {
+	configHash uint64
+	imageHash  uint64
+	result     DetectionResult
+}
+
+// NewCachingAnalyzer returns a CachingAnalyzer keeping at most capacity
+// recent results, treating two frames as the same scene when their 8x8
+// dHashes differ by at most hammingThreshold bits.
+func NewCachingAnalyzer(capacity, hammingThreshold int) *CachingAnalyzer {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &CachingAnalyzer{
+		capacity:  capacity,
+		threshold: hammingThreshold,
+		entries:   make([]cacheEntry, 0, capacity),
+	}
+}
+
+// AnalyzePhoto returns a DetectionResult for photoPath under config, reusing
+// a cached result (with CacheHit set) when a recent frame analyzed under an
+// identical DetectionConfig has a dHash within the configured Hamming
+// distance. LatencyMs always reflects the time this call took, including
+// cache lookup, so a cache hit is visible as an unusually low latency.
+func (c *CachingAnalyzer) AnalyzePhoto(photoPath string, config *DetectionConfig) (*DetectionResult, error) {
+	startTime := time.Now()
+	configHash := hashDetectionConfig(config)
+
+	imageHash, hashErr := computeDHash(photoPath)
+	if hashErr == nil {
+		c.mu.Lock()
+		if idx, dist, ok := c.findNearMatch(configHash, imageHash); ok {
+			cached := c.entries[idx].result
+			c.promote(idx)
+			if dist == 0 {
+				c.hits++
+			} else {
+				c.nearHits++
+			}
+			c.mu.Unlock()
+			cached.CacheHit = true
+			cached.LatencyMs = time.Since(startTime).Milliseconds()
+			return &cached, nil
+		}
+		c.misses++
+		c.mu.Unlock()
+	}
+
+	result, err := AnalyzePhotoWithConfig(photoPath, config)
+	if err != nil {
+		return nil, err
+	}
+	result.CacheHit = false
+	result.LatencyMs = time.Since(startTime).Milliseconds()
+
+	if hashErr == nil {
+		c.mu.Lock()
+		c.insert(cacheEntry{configHash: configHash, imageHash: imageHash, result: *result})
+		c.mu.Unlock()
+	}
+	return result, nil
+}
+
+// findNearMatch returns the index and Hamming distance of the entry
+// matching configHash whose imageHash is closest to imageHash, if any such
+// entry is within c.threshold bits. Callers must hold c.mu.
+func (c *CachingAnalyzer) findNearMatch(configHash, imageHash uint64) (int, int, bool) {
+	best := -1
+	bestDist := -1
+	for i, e := range c.entries {
+		if e.configHash != configHash {
+			continue
+		}
+		dist := bits.OnesCount64(e.imageHash ^ imageHash)
+		if dist <= c.threshold && (best == -1 || dist < bestDist) {
+			best = i
+			bestDist = dist
+		}
+	}
+	return best, bestDist, best != -1
+}
+
+// promote moves the entry at idx to the end of c.entries (most recently
+// used). Callers must hold c.mu.
+func (c *CachingAnalyzer) promote(idx int) {
+	e := c.entries[idx]
+	c.entries = append(c.entries[:idx], c.entries[idx+1:]...)
+	c.entries = append(c.entries, e)
+}
+
+// insert appends e as the most recently used entry, evicting the least
+// recently used one first if the cache is already at capacity. Callers must
+// hold c.mu.
+func (c *CachingAnalyzer) insert(e cacheEntry) {
+	if len(c.entries) >= c.capacity {
+		c.entries = c.entries[1:]
+	}
+	c.entries = append(c.entries, e)
+}
+
+// Purge empties the cache and resets its hit/miss/near-hit counters.
+func (c *CachingAnalyzer) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = c.entries[:0]
+	c.hits, c.nearHits, c.misses = 0, 0, 0
+}
+
+// Stats returns the cumulative hit, near-hit, and miss counts observed by
+// this analyzer, so an operator can tune capacity and the Hamming threshold.
+func (c *CachingAnalyzer) Stats() (hits, nearHits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.nearHits, c.misses
+}
+
+// computeDHash computes an 8x8 difference hash (dHash) of the photo at
+// photoPath: the image is downscaled to 9x8 grayscale and each bit records
+// whether a pixel is darker than its right-hand neighbor. dHash is robust
+// to the small brightness/compression changes between consecutive
+// timelapse frames of an otherwise static scene, unlike a byte-exact
+// comparison of the source file.
+func computeDHash(photoPath string) (uint64, error) {
+	file, err := os.Open(photoPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open photo: %v", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	small := preprocess.Resize(img, 9, 8, preprocess.Bilinear)
+
+	var hash uint64
+	var bit uint
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			left := luminanceAt(small, x, y)
+			right := luminanceAt(small, x+1, y)
+			if left < right {
+				hash |= 1 << bit
+			}
+			bit++
+		}
+	}
+	return hash, nil
+}
+
+func luminanceAt(img image.Image, x, y int) float64 {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+}
+
+// hashDetectionConfig folds a DetectionConfig's fields into a single FNV-1a
+// hash, so cached results are never served across two configs (e.g. one
+// with UseOpenCV and one without) that could disagree on what a frame
+// contains.
+func hashDetectionConfig(config *DetectionConfig) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%+v", config)
+	return h.Sum64()
+}
@@ -0,0 +1,107 @@
+package detection
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestCachingAnalyzerHitsOnIdenticalFrame(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestJPEG(t, dir, "a.jpg", color.RGBA{100, 100, 100, 255})
+	b := writeTestJPEG(t, dir, "b.jpg", color.RGBA{100, 100, 100, 255})
+
+	cache := NewCachingAnalyzer(8, 4)
+	config := &DetectionConfig{UseOpenCV: false}
+
+	first, err := cache.AnalyzePhoto(a, config)
+	if err != nil {
+		t.Fatalf("AnalyzePhoto returned an error: %v", err)
+	}
+	if first.CacheHit {
+		t.Error("first call for an empty cache should not be a cache hit")
+	}
+
+	second, err := cache.AnalyzePhoto(b, config)
+	if err != nil {
+		t.Fatalf("AnalyzePhoto returned an error: %v", err)
+	}
+	if !second.CacheHit {
+		t.Error("an identical follow-up frame should be served from the cache")
+	}
+
+	hits, nearHits, misses := cache.Stats()
+	if hits != 1 || nearHits != 0 || misses != 1 {
+		t.Errorf("Stats() = (%d, %d, %d), expected (1, 0, 1)", hits, nearHits, misses)
+	}
+}
+
+func TestCachingAnalyzerMissesOnDifferentFrame(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestJPEG(t, dir, "a.jpg", color.RGBA{10, 10, 10, 255})
+	b := writeHalfChangedTestJPEG(t, dir, "b.jpg", color.RGBA{10, 10, 10, 255}, color.RGBA{240, 240, 240, 255})
+
+	cache := NewCachingAnalyzer(8, 2)
+	config := &DetectionConfig{UseOpenCV: false}
+
+	if _, err := cache.AnalyzePhoto(a, config); err != nil {
+		t.Fatalf("AnalyzePhoto returned an error: %v", err)
+	}
+	result, err := cache.AnalyzePhoto(b, config)
+	if err != nil {
+		t.Fatalf("AnalyzePhoto returned an error: %v", err)
+	}
+	if result.CacheHit {
+		t.Error("a substantially different frame should not be served from the cache")
+	}
+
+	_, _, misses := cache.Stats()
+	if misses != 2 {
+		t.Errorf("expected 2 misses, got %d", misses)
+	}
+}
+
+func TestCachingAnalyzerSeparatesConfigs(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestJPEG(t, dir, "a.jpg", color.RGBA{50, 60, 70, 255})
+	b := writeTestJPEG(t, dir, "b.jpg", color.RGBA{50, 60, 70, 255})
+
+	cache := NewCachingAnalyzer(8, 4)
+
+	if _, err := cache.AnalyzePhoto(a, &DetectionConfig{UseOpenCV: false}); err != nil {
+		t.Fatalf("AnalyzePhoto returned an error: %v", err)
+	}
+	result, err := cache.AnalyzePhoto(b, &DetectionConfig{UseOpenCV: true, Timeout: 1})
+	if err != nil {
+		t.Fatalf("AnalyzePhoto returned an error: %v", err)
+	}
+	if result.CacheHit {
+		t.Error("an identical frame analyzed under a different config should not hit the cache")
+	}
+}
+
+func TestCachingAnalyzerPurge(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestJPEG(t, dir, "a.jpg", color.RGBA{5, 5, 5, 255})
+	b := writeTestJPEG(t, dir, "b.jpg", color.RGBA{5, 5, 5, 255})
+
+	cache := NewCachingAnalyzer(8, 4)
+	config := &DetectionConfig{UseOpenCV: false}
+
+	if _, err := cache.AnalyzePhoto(a, config); err != nil {
+		t.Fatalf("AnalyzePhoto returned an error: %v", err)
+	}
+	cache.Purge()
+
+	result, err := cache.AnalyzePhoto(b, config)
+	if err != nil {
+		t.Fatalf("AnalyzePhoto returned an error: %v", err)
+	}
+	if result.CacheHit {
+		t.Error("a purged cache should not serve a hit for a previously seen frame")
+	}
+
+	hits, nearHits, misses := cache.Stats()
+	if hits != 0 || nearHits != 0 || misses != 1 {
+		t.Errorf("Stats() after purge = (%d, %d, %d), expected (0, 0, 1)", hits, nearHits, misses)
+	}
+}
@@ -0,0 +1,484 @@
+package detection
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Cascade is a parsed Haar cascade classifier, loaded from an XML file in
+// the style of OpenCV's standard cascades (e.g.
+// haarcascade_frontalface_default.xml). Detect slides a Cascade across an
+// image at multiple scales to find matches, entirely in-process.
+//
+// This loader supports the common subset of the OpenCV cascade schema: each
+// stage is a list of weak classifiers, and each weak classifier is a single
+// rectangle-sum feature compared against a threshold with two leaf values.
+// Tilted (45-degree) features are parsed but not evaluated, since scoring
+// them requires a second, rotated integral image that this package doesn't
+// build; cascades relying heavily on tilted features will under-detect.
+type Cascade struct {
+	WindowWidth  int
+	WindowHeight int
+	Stages       []Stage
+}
+
+// Stage is one boosted stage of the cascade. A window is rejected as soon
+// as the summed weak-classifier output for a stage falls below Threshold.
+type Stage struct {
+	Threshold       float64
+	WeakClassifiers []WeakClassifier
+}
+
+// WeakClassifier compares Feature's normalized rectangle sum against
+// Threshold, contributing LeftValue below the threshold or RightValue at or
+// above it to the enclosing stage's sum.
+type WeakClassifier struct {
+	Feature    Feature
+	Threshold  float64
+	LeftValue  float64
+	RightValue float64
+}
+
+// Feature is a Haar-like rectangle feature: the weighted sum of pixel
+// intensities across Rects, in coordinates relative to the cascade's
+// WindowWidth x WindowHeight.
+type Feature struct {
+	Rects  []Rect
+	Tilted bool
+}
+
+// Rect is one weighted rectangle of a Feature.
+type Rect struct {
+	X, Y, Width, Height int
+	Weight              float64
+}
+
+type xmlStorage struct {
+	XMLName xml.Name   `xml:"opencv_storage"`
+	Cascade xmlCascade `xml:"cascade"`
+}
+
+type xmlCascade struct {
+	Width  int        `xml:"width"`
+	Height int        `xml:"height"`
+	Stages []xmlStage `xml:"stages>_"`
+}
+
+type xmlStage struct {
+	Threshold       float64             `xml:"stageThreshold"`
+	WeakClassifiers []xmlWeakClassifier `xml:"weakClassifiers>_"`
+}
+
+type xmlWeakClassifier struct {
+	Threshold  float64    `xml:"threshold"`
+	LeftValue  float64    `xml:"leftValue"`
+	RightValue float64    `xml:"rightValue"`
+	Feature    xmlFeature `xml:"feature"`
+}
+
+type xmlFeature struct {
+	Tilted int      `xml:"tilted"`
+	Rects  []string `xml:"rects>_"`
+}
+
+// LoadCascade reads and parses an OpenCV-style Haar cascade XML file.
+func LoadCascade(path string) (*Cascade, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cascade file: %v", err)
+	}
+	defer f.Close()
+	return ParseCascade(f)
+}
+
+// ParseCascade parses an OpenCV-style Haar cascade XML document from r.
+func ParseCascade(r io.Reader) (*Cascade, error) {
+	var storage xmlStorage
+	if err := xml.NewDecoder(r).Decode(&storage); err != nil {
+		return nil, fmt.Errorf("failed to parse cascade XML: %v", err)
+	}
+
+	cascade := &Cascade{
+		WindowWidth:  storage.Cascade.Width,
+		WindowHeight: storage.Cascade.Height,
+	}
+	if cascade.WindowWidth <= 0 || cascade.WindowHeight <= 0 {
+		return nil, fmt.Errorf("cascade is missing a valid window size")
+	}
+
+	for _, xs := range storage.Cascade.Stages {
+		stage := Stage{Threshold: xs.Threshold}
+		for _, xwc := range xs.WeakClassifiers {
+			feature, err := parseFeature(xwc.Feature)
+			if err != nil {
+				return nil, err
+			}
+			stage.WeakClassifiers = append(stage.WeakClassifiers, WeakClassifier{
+				Feature:    feature,
+				Threshold:  xwc.Threshold,
+				LeftValue:  xwc.LeftValue,
+				RightValue: xwc.RightValue,
+			})
+		}
+		cascade.Stages = append(cascade.Stages, stage)
+	}
+
+	return cascade, nil
+}
+
+// parseFeature converts an xmlFeature's whitespace-separated "x y w h
+// weight" rect lines into Rects.
+func parseFeature(xf xmlFeature) (Feature, error) {
+	feature := Feature{Tilted: xf.Tilted != 0}
+	for _, line := range xf.Rects {
+		fields := strings.Fields(line)
+		if len(fields) != 5 {
+			return Feature{}, fmt.Errorf("malformed rect %q: expected 5 fields, got %d", line, len(fields))
+		}
+		x, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return Feature{}, fmt.Errorf("malformed rect %q: %v", line, err)
+		}
+		y, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return Feature{}, fmt.Errorf("malformed rect %q: %v", line, err)
+		}
+		w, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return Feature{}, fmt.Errorf("malformed rect %q: %v", line, err)
+		}
+		h, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return Feature{}, fmt.Errorf("malformed rect %q: %v", line, err)
+		}
+		weight, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return Feature{}, fmt.Errorf("malformed rect %q: %v", line, err)
+		}
+		feature.Rects = append(feature.Rects, Rect{X: x, Y: y, Width: w, Height: h, Weight: weight})
+	}
+	return feature, nil
+}
+
+// SummedAreaTable holds an integral image and a squared-value integral
+// image built in a single pass over img's luminance plane, so the mean and
+// standard deviation of any window can be computed in O(1).
+type SummedAreaTable struct {
+	sum    [][]int64
+	sqSum  [][]int64
+	width  int
+	height int
+}
+
+// NewSummedAreaTable builds a SummedAreaTable over img's grayscale plane.
+func NewSummedAreaTable(img image.Image) *SummedAreaTable {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	sum := make([][]int64, height+1)
+	sqSum := make([][]int64, height+1)
+	for y := range sum {
+		sum[y] = make([]int64, width+1)
+		sqSum[y] = make([]int64, width+1)
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			lum := int64(0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8))
+			sum[y+1][x+1] = lum + sum[y][x+1] + sum[y+1][x] - sum[y][x]
+			sqSum[y+1][x+1] = lum*lum + sqSum[y][x+1] + sqSum[y+1][x] - sqSum[y][x]
+		}
+	}
+
+	return &SummedAreaTable{sum: sum, sqSum: sqSum, width: width, height: height}
+}
+
+// RectSum returns the sum of luminance values within the [x,x+w) x [y,y+h)
+// rectangle, clamped to the table's bounds.
+func (s *SummedAreaTable) RectSum(x, y, w, h int) int64 {
+	x0, y0, x1, y1 := s.clamp(x, y, w, h)
+	return s.sum[y1][x1] - s.sum[y0][x1] - s.sum[y1][x0] + s.sum[y0][x0]
+}
+
+// WindowMeanStdDev returns the mean and standard deviation of luminance
+// values within the [x,x+size) x [y,y+size) window.
+func (s *SummedAreaTable) WindowMeanStdDev(x, y, size int) (mean, stddev float64) {
+	x0, y0, x1, y1 := s.clamp(x, y, size, size)
+	area := float64((x1 - x0) * (y1 - y0))
+	if area <= 0 {
+		return 0, 0
+	}
+
+	total := s.sum[y1][x1] - s.sum[y0][x1] - s.sum[y1][x0] + s.sum[y0][x0]
+	sqTotal := s.sqSum[y1][x1] - s.sqSum[y0][x1] - s.sqSum[y1][x0] + s.sqSum[y0][x0]
+
+	mean = float64(total) / area
+	variance := float64(sqTotal)/area - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return mean, math.Sqrt(variance)
+}
+
+func (s *SummedAreaTable) clamp(x, y, w, h int) (x0, y0, x1, y1 int) {
+	x0, y0 = clampInt(x, 0, s.width), clampInt(y, 0, s.height)
+	x1, y1 = clampInt(x+w, 0, s.width), clampInt(y+h, 0, s.height)
+	return x0, y0, x1, y1
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// ScanOpts controls how Detect slides a Cascade across an image.
+type ScanOpts struct {
+	ScaleFactor  float64 // window growth per scale step, e.g. 1.1; defaults to 1.1 if <= 1.0
+	MinNeighbors int     // overlapping hits required to keep a detection; defaults to 3 if <= 0
+	MinSize      int     // smallest window size in pixels; defaults to the cascade's native size
+	MaxSize      int     // largest window size in pixels; defaults to the smaller image dimension
+}
+
+// DetectionHit pairs a detected BoundingBox with a confidence score derived
+// from how far the cascade's final stage sum exceeded its threshold.
+type DetectionHit struct {
+	BBox       BoundingBox
+	Confidence float32
+}
+
+// Detect slides cascade across img at multiple scales and returns the
+// bounding box of every group of overlapping matches with at least
+// opts.MinNeighbors members. Use DetectWithConfidence for per-box confidence
+// scores.
+func Detect(img image.Image, cascade *Cascade, opts ScanOpts) []BoundingBox {
+	hits := DetectWithConfidence(img, cascade, opts)
+	boxes := make([]BoundingBox, len(hits))
+	for i, h := range hits {
+		boxes[i] = h.BBox
+	}
+	return boxes
+}
+
+// DetectWithConfidence is Detect, additionally reporting a confidence score
+// per returned box.
+func DetectWithConfidence(img image.Image, cascade *Cascade, opts ScanOpts) []DetectionHit {
+	if opts.ScaleFactor <= 1.0 {
+		opts.ScaleFactor = 1.1
+	}
+	if opts.MinNeighbors <= 0 {
+		opts.MinNeighbors = 3
+	}
+
+	bounds := img.Bounds()
+	imgW, imgH := bounds.Dx(), bounds.Dy()
+
+	minSize := opts.MinSize
+	if minSize <= 0 {
+		minSize = cascade.WindowWidth
+	}
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = imgW
+		if imgH < maxSize {
+			maxSize = imgH
+		}
+	}
+	if minSize <= 0 || minSize > maxSize {
+		return nil
+	}
+
+	sat := NewSummedAreaTable(img)
+
+	var candidates []DetectionHit
+	for windowSize := minSize; windowSize <= maxSize; {
+		scale := float64(windowSize) / float64(cascade.WindowWidth)
+		stride := windowSize / 10
+		if stride < 1 {
+			stride = 1
+		}
+
+		for y := 0; y+windowSize <= imgH; y += stride {
+			for x := 0; x+windowSize <= imgW; x += stride {
+				if confidence, ok := evalCascade(cascade, sat, x, y, windowSize, scale); ok {
+					candidates = append(candidates, DetectionHit{
+						BBox:       BoundingBox{X: x, Y: y, Width: windowSize, Height: windowSize},
+						Confidence: float32(confidence),
+					})
+				}
+			}
+		}
+
+		next := int(float64(windowSize) * opts.ScaleFactor)
+		if next <= windowSize {
+			next = windowSize + 1
+		}
+		windowSize = next
+	}
+
+	return groupDetections(candidates, opts.MinNeighbors)
+}
+
+// evalCascade evaluates every stage of c against the window at (x, y) with
+// the given windowSize and scale (windowSize / c.WindowWidth), short
+// circuiting as soon as a stage sum falls below its threshold. ok is false
+// if the window was rejected; otherwise confidence is the final stage's
+// margin above its threshold.
+func evalCascade(c *Cascade, sat *SummedAreaTable, x, y, windowSize int, scale float64) (confidence float64, ok bool) {
+	_, stddev := sat.WindowMeanStdDev(x, y, windowSize)
+	if stddev < 1e-6 {
+		return 0, false
+	}
+
+	var margin float64
+	for _, stage := range c.Stages {
+		var stageSum float64
+		for _, wc := range stage.WeakClassifiers {
+			// Rects in a well-formed cascade feature have their weighted
+			// areas sum to (near) zero, so the raw sum is already invariant
+			// to a constant added to every pixel; dividing by stddev alone
+			// normalizes for window contrast.
+			featureSum := evalFeature(sat, wc.Feature, x, y, scale) / stddev
+			if featureSum < wc.Threshold {
+				stageSum += wc.LeftValue
+			} else {
+				stageSum += wc.RightValue
+			}
+		}
+		margin = stageSum - stage.Threshold
+		if margin < 0 {
+			return 0, false
+		}
+	}
+	return margin, true
+}
+
+// evalFeature computes the raw (non-normalized) weighted rectangle sum of a
+// feature at window position (x, y), scaling each rect by scale. Tilted
+// rects are skipped; see the Cascade doc comment.
+func evalFeature(sat *SummedAreaTable, f Feature, x, y int, scale float64) float64 {
+	if f.Tilted {
+		return 0
+	}
+	var sum float64
+	for _, r := range f.Rects {
+		rx := x + int(float64(r.X)*scale)
+		ry := y + int(float64(r.Y)*scale)
+		rw := int(float64(r.Width) * scale)
+		rh := int(float64(r.Height) * scale)
+		if rw <= 0 || rh <= 0 {
+			continue
+		}
+		sum += r.Weight * float64(sat.RectSum(rx, ry, rw, rh))
+	}
+	return sum
+}
+
+// groupDetections merges overlapping hits (intersection-over-min-area >
+// 0.3) and keeps only groups with at least minNeighbors members, averaging
+// each group's boxes and taking the highest confidence within the group.
+func groupDetections(hits []DetectionHit, minNeighbors int) []DetectionHit {
+	n := len(hits)
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if overlaps(hits[i].BBox, hits[j].BBox) {
+				ri, rj := find(i), find(j)
+				if ri != rj {
+					parent[ri] = rj
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := 0; i < n; i++ {
+		root := find(i)
+		groups[root] = append(groups[root], i)
+	}
+
+	var result []DetectionHit
+	for _, idxs := range groups {
+		if len(idxs) < minNeighbors {
+			continue
+		}
+		var sumX, sumY, sumW, sumH int
+		var bestConfidence float32
+		for _, idx := range idxs {
+			b := hits[idx].BBox
+			sumX += b.X
+			sumY += b.Y
+			sumW += b.Width
+			sumH += b.Height
+			if hits[idx].Confidence > bestConfidence {
+				bestConfidence = hits[idx].Confidence
+			}
+		}
+		count := len(idxs)
+		result = append(result, DetectionHit{
+			BBox: BoundingBox{
+				X:      sumX / count,
+				Y:      sumY / count,
+				Width:  sumW / count,
+				Height: sumH / count,
+			},
+			Confidence: bestConfidence,
+		})
+	}
+	return result
+}
+
+// overlaps reports whether a and b overlap enough (intersection area over
+// the smaller box's area) to be considered the same detection.
+func overlaps(a, b BoundingBox) bool {
+	ix0, iy0 := maxInt(a.X, b.X), maxInt(a.Y, b.Y)
+	ix1, iy1 := minInt(a.X+a.Width, b.X+b.Width), minInt(a.Y+a.Height, b.Y+b.Height)
+	if ix1 <= ix0 || iy1 <= iy0 {
+		return false
+	}
+	interArea := (ix1 - ix0) * (iy1 - iy0)
+	minArea := a.Width * a.Height
+	if bArea := b.Width * b.Height; bArea < minArea {
+		minArea = bArea
+	}
+	if minArea <= 0 {
+		return false
+	}
+	return float64(interArea)/float64(minArea) > 0.3
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
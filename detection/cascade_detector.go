@@ -0,0 +1,75 @@
+package detection
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"strings"
+)
+
+// analyzeWithGoCascade performs object detection using the pure-Go Haar
+// cascade detector (see cascade.go) against every cascade in
+// config.CascadePaths. It has no external dependencies, unlike
+// analyzeWithOpenCV (Python subprocess) and analyzeWithNativeOpenCV (gocv
+// build tag), so it's tried first when enabled.
+func analyzeWithGoCascade(photoPath string, config *DetectionConfig) (*DetectionResult, error) {
+	if len(config.CascadePaths) == 0 {
+		return nil, fmt.Errorf("no cascade paths configured")
+	}
+
+	file, err := os.Open(photoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open photo: %v", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	result := &DetectionResult{
+		PhotoPath: photoPath,
+		Objects:   []string{},
+		Details:   []ObjectDetail{},
+	}
+	result.IsDay = analyzeTimeOfDayEnhanced(img)
+
+	opts := ScanOpts{ScaleFactor: 1.1, MinNeighbors: 3}
+	for _, cascadePath := range config.CascadePaths {
+		cascade, err := LoadCascade(cascadePath)
+		if err != nil {
+			log.Printf("Skipping cascade '%s': %v", cascadePath, err)
+			continue
+		}
+
+		className := cascadeClassName(cascadePath)
+		for _, hit := range DetectWithConfidence(img, cascade, opts) {
+			bbox := hit.BBox
+			result.Objects = append(result.Objects, className)
+			result.Details = append(result.Details, ObjectDetail{
+				Class:      className,
+				Confidence: hit.Confidence,
+				Category:   categorizeObject(className),
+				BBox:       &bbox,
+			})
+		}
+	}
+
+	result.Summary = generateSummary(result.IsDay, result.Objects)
+	return result, nil
+}
+
+// cascadeClassName derives a readable object class name from a cascade XML
+// file's name, e.g. "haarcascade_frontalface_default.xml" becomes
+// "frontalface default".
+func cascadeClassName(cascadePath string) string {
+	base := cascadePath
+	if i := strings.LastIndexAny(base, "/\\"); i >= 0 {
+		base = base[i+1:]
+	}
+	base = strings.TrimSuffix(base, ".xml")
+	base = strings.TrimPrefix(base, "haarcascade_")
+	return strings.ReplaceAll(base, "_", " ")
+}
@@ -0,0 +1,146 @@
+package detection
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+const testCascadeXML = `<?xml version="1.0"?>
+<opencv_storage>
+<cascade>
+  <width>20</width>
+  <height>20</height>
+  <stages>
+    <_>
+      <stageThreshold>-1.0</stageThreshold>
+      <weakClassifiers>
+        <_>
+          <threshold>0.0</threshold>
+          <leftValue>-1.0</leftValue>
+          <rightValue>1.0</rightValue>
+          <feature>
+            <tilted>0</tilted>
+            <rects>
+              <_>0 0 20 10 -1.</_>
+              <_>0 10 20 10 1.</_>
+            </rects>
+          </feature>
+        </_>
+      </weakClassifiers>
+    </_>
+  </stages>
+</cascade>
+</opencv_storage>
+`
+
+func TestParseCascade(t *testing.T) {
+	cascade, err := ParseCascade(strings.NewReader(testCascadeXML))
+	if err != nil {
+		t.Fatalf("ParseCascade returned an error: %v", err)
+	}
+	if cascade.WindowWidth != 20 || cascade.WindowHeight != 20 {
+		t.Fatalf("Expected a 20x20 window, got %dx%d", cascade.WindowWidth, cascade.WindowHeight)
+	}
+	if len(cascade.Stages) != 1 {
+		t.Fatalf("Expected 1 stage, got %d", len(cascade.Stages))
+	}
+
+	stage := cascade.Stages[0]
+	if stage.Threshold != -1.0 {
+		t.Errorf("Expected stage threshold -1.0, got %v", stage.Threshold)
+	}
+	if len(stage.WeakClassifiers) != 1 {
+		t.Fatalf("Expected 1 weak classifier, got %d", len(stage.WeakClassifiers))
+	}
+
+	feature := stage.WeakClassifiers[0].Feature
+	if feature.Tilted {
+		t.Error("Expected a non-tilted feature")
+	}
+	if len(feature.Rects) != 2 {
+		t.Fatalf("Expected 2 rects, got %d", len(feature.Rects))
+	}
+	if feature.Rects[0].Weight != -1.0 || feature.Rects[1].Weight != 1.0 {
+		t.Errorf("Unexpected rect weights: %+v", feature.Rects)
+	}
+}
+
+func TestParseCascadeMissingWindowSize(t *testing.T) {
+	xml := `<?xml version="1.0"?><opencv_storage><cascade></cascade></opencv_storage>`
+	if _, err := ParseCascade(strings.NewReader(xml)); err == nil {
+		t.Error("Expected an error for a cascade with no window size")
+	}
+}
+
+// detectionTestCascade is a single-stage cascade whose sole feature fires on
+// a horizontal top-dark/bottom-bright edge, like the image built in
+// TestDetectWithConfidence_FindsContrastWindow.
+func detectionTestCascade(t *testing.T) *Cascade {
+	t.Helper()
+	cascade, err := ParseCascade(strings.NewReader(testCascadeXML))
+	if err != nil {
+		t.Fatalf("failed to parse test cascade: %v", err)
+	}
+	return cascade
+}
+
+func TestDetectWithConfidence_FindsContrastWindow(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 60, 60))
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 60; x++ {
+			if y < 30 {
+				img.Set(x, y, color.RGBA{20, 20, 20, 255})
+			} else {
+				img.Set(x, y, color.RGBA{220, 220, 220, 255})
+			}
+		}
+	}
+
+	cascade := detectionTestCascade(t)
+	hits := DetectWithConfidence(img, cascade, ScanOpts{MinSize: 20, MaxSize: 20, MinNeighbors: 1})
+	if len(hits) == 0 {
+		t.Fatal("Expected at least one detection for a strong top-dark/bottom-bright window")
+	}
+	for _, hit := range hits {
+		if hit.BBox.X < 0 || hit.BBox.X+hit.BBox.Width > 60 || hit.BBox.Y < 0 || hit.BBox.Y+hit.BBox.Height > 60 {
+			t.Errorf("Detection out of image bounds: %+v", hit.BBox)
+		}
+	}
+}
+
+func TestDetectWithConfidence_UniformImageNoDetections(t *testing.T) {
+	img := createTestImage(60, 60, color.RGBA{128, 128, 128, 255})
+
+	cascade := detectionTestCascade(t)
+	hits := DetectWithConfidence(img, cascade, ScanOpts{MinSize: 20, MaxSize: 20, MinNeighbors: 1})
+	if len(hits) != 0 {
+		t.Errorf("Expected no detections on a uniform image, got %v", hits)
+	}
+}
+
+func TestGroupDetections_RequiresMinNeighbors(t *testing.T) {
+	hits := []DetectionHit{
+		{BBox: BoundingBox{X: 0, Y: 0, Width: 20, Height: 20}, Confidence: 0.5},
+	}
+	if groups := groupDetections(hits, 2); len(groups) != 0 {
+		t.Errorf("Expected no groups below MinNeighbors, got %v", groups)
+	}
+	if groups := groupDetections(hits, 1); len(groups) != 1 {
+		t.Errorf("Expected one group at MinNeighbors 1, got %v", groups)
+	}
+}
+
+func TestCascadeClassName(t *testing.T) {
+	cases := map[string]string{
+		"haarcascade_frontalface_default.xml": "frontalface default",
+		"/models/haarcascade_eye.xml":          "eye",
+		"custom.xml":                           "custom",
+	}
+	for path, expected := range cases {
+		if got := cascadeClassName(path); got != expected {
+			t.Errorf("cascadeClassName(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
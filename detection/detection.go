@@ -7,6 +7,7 @@ import (
 	"image"
 	_ "image/jpeg" // Register JPEG format
 	_ "image/png"  // Register PNG format
+	"io"
 	"log"
 	"math"
 	"os"
@@ -15,6 +16,9 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	_ "github.com/ingojaeckel/go-raspberry-pi-timelapse/imagefmt" // Register BMP and TIFF formats
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/preprocess"
 )
 
 // DetectionResult represents the results of object detection analysis
@@ -26,6 +30,7 @@ type DetectionResult struct {
 	Details           []ObjectDetail `json:"details,omitempty"`
 	LatencyMs         int64    `json:"latencyMs"`         // Detection time in milliseconds
 	OverallConfidence float32  `json:"overallConfidence"` // Overall confidence score (0.0-1.0)
+	CacheHit          bool     `json:"cacheHit"`          // True if served from CachingAnalyzer's perceptual-hash cache
 }
 
 // ObjectDetail provides detailed information about detected objects
@@ -88,21 +93,45 @@ func AnalyzePhotoWithConfig(photoPath string, config *DetectionConfig) (*Detecti
 
 	var result *DetectionResult
 	var err error
-	
-	// Try OpenCV-based detection first if enabled
-	if config.UseOpenCV {
-		if result, err = analyzeWithOpenCV(photoPath, config.Timeout); err == nil {
+
+	// Apply EXIF-orientation correction and bounded downscaling once, up
+	// front, when configured. A nil preprocessedImg leaves every path below
+	// reading photoPath directly, so leaving Preprocess at its zero value is
+	// fully inert.
+	var preprocessedImg image.Image
+	if config.Preprocess.MaxAnalysisDimension > 0 || config.Preprocess.RespectEXIF {
+		if preprocessedImg, err = preprocess.Process(photoPath, config.Preprocess); err != nil {
+			log.Printf("Preprocessing failed (%v), analyzing original photo", err)
+			preprocessedImg = nil
+		}
+	}
+
+	// Try the pure-Go cascade detector first if enabled, since it has no
+	// external dependencies and so is preferred when available.
+	if config.UseGoCascade {
+		if result, err = analyzeWithGoCascade(photoPath, config); err == nil {
+			log.Printf("Using Go Haar cascade detector for object detection")
+		} else {
+			log.Printf("Go cascade detection failed (%v), falling back", err)
+		}
+	}
+
+	// Try OpenCV-based detection next if enabled and the cascade detector
+	// didn't already produce a result
+	if result == nil && config.UseOpenCV {
+		if result, err = analyzeWithOpenCV(photoPath, config.Timeout, preprocessedImg); err == nil {
 			log.Printf("Using OpenCV for high-accuracy object detection")
 		} else {
 			log.Printf("OpenCV detection failed (%v), falling back to enhanced analysis", err)
 			// Fallback to original enhanced detection
-			result, err = analyzeWithEnhancedDetection(photoPath)
+			result, err = analyzeWithEnhancedDetection(photoPath, preprocessedImg)
 		}
-	} else {
+	} else if result == nil {
 		// Use enhanced detection directly
-		result, err = analyzeWithEnhancedDetection(photoPath)
+		result, err = analyzeWithEnhancedDetection(photoPath, preprocessedImg)
 	}
-	
+
+
 	if err != nil {
 		return nil, err
 	}
@@ -121,14 +150,73 @@ func AnalyzePhotoWithConfig(photoPath string, config *DetectionConfig) (*Detecti
 	return result, nil
 }
 
+// AnalyzeReader decodes an in-memory image (e.g. an HTTP upload body) and
+// runs AnalyzePhotoWithConfig against it with the default configuration,
+// without requiring the caller to first write it to disk. hintFormat is
+// used only for error messages; the actual format is determined by
+// image.Decode via the registered decoders (JPEG/PNG/BMP/TIFF).
+//
+// Every detection path below this point still expects a file on disk (the
+// OpenCV script shells out to a path, and analyzeWithEnhancedDetection's
+// PhotoPath field is meant to be a real path), so the decoded image is
+// staged to a temporary JPEG first, the same way preprocess.WriteTempJPEG
+// stages a preprocessed frame for the OpenCV subprocess.
+func AnalyzeReader(r io.Reader, hintFormat string) (*DetectionResult, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s image: %v", hintFormat, err)
+	}
+
+	tempPath, err := preprocess.WriteTempJPEG(img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage decoded image for analysis: %v", err)
+	}
+	defer os.Remove(tempPath)
+
+	return AnalyzePhotoWithConfig(tempPath, nil)
+}
+
 // DetectionConfig holds configuration for object detection
 type DetectionConfig struct {
 	UseOpenCV bool          // Whether to try OpenCV detection first
 	Timeout   time.Duration // Maximum time for detection
+
+	// Native OpenCV (opencv build tag) model selection. ModelPath may point at
+	// either a Darknet .weights file (paired with ModelConfigPath) or a YOLOv8
+	// .onnx export; the extension determines which loader is used. Left empty,
+	// the detector falls back to probing the legacy /opt/yolo/ locations.
+	ModelPath           string
+	ModelConfigPath     string // Darknet .cfg path; ignored for ONNX models
+	ModelClassesPath    string
+	InputSize           int
+	ConfidenceThreshold float32
+	NMSThreshold        float32
+
+	// UseGoCascade enables the pure-Go Haar cascade detector (see cascade.go)
+	// as a dependency-free alternative to the OpenCV paths above. When set,
+	// it is tried before UseOpenCV so installations without Python/OpenCV or
+	// the gocv build tag can still get real bounding boxes.
+	UseGoCascade bool
+	CascadePaths []string // Paths to cascade XML files to scan with, e.g. haarcascade_frontalface_default.xml
+
+	// Preprocess controls EXIF-orientation correction and downscaling applied
+	// to the photo before the OpenCV script and enhanced-analysis paths see it
+	// (see the preprocess package); the Go cascade path reads the original
+	// file. Its zero value disables preprocessing entirely, so existing
+	// callers see no change in behavior unless they opt in.
+	Preprocess preprocess.Config
+
+	// Camera identifies which camera's change-detection reference frame to
+	// diff against in the pure-Go Sauvola fallback (see sauvola.go and
+	// analyzeWithNativeOpenCV in opencv_detector_fallback.go). Left empty,
+	// all callers share a single baseline.
+	Camera string
 }
 
-// analyzeWithOpenCV performs object detection using the OpenCV Python script
-func analyzeWithOpenCV(photoPath string, timeout time.Duration) (*DetectionResult, error) {
+// analyzeWithOpenCV performs object detection using the OpenCV Python script.
+// When preprocessed is non-nil, the Python script analyzes it (written out
+// to a temporary JPEG) instead of the original file at photoPath.
+func analyzeWithOpenCV(photoPath string, timeout time.Duration, preprocessed image.Image) (*DetectionResult, error) {
 	// Get the directory where this Go file is located
 	_, currentFile, _, ok := runtime.Caller(0)
 	if !ok {
@@ -136,18 +224,29 @@ func analyzeWithOpenCV(photoPath string, timeout time.Duration) (*DetectionResul
 	}
 	detectionDir := filepath.Dir(currentFile)
 	scriptPath := filepath.Join(detectionDir, "opencv_detector.py")
-	
+
 	// Check if the Python script exists
 	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("OpenCV detection script not found: %s", scriptPath)
 	}
-	
+
+	analysisPath := photoPath
+	if preprocessed != nil {
+		tempPath, err := preprocess.WriteTempJPEG(preprocessed)
+		if err != nil {
+			log.Printf("Failed to write preprocessed photo (%v), analyzing original", err)
+		} else {
+			defer os.Remove(tempPath)
+			analysisPath = tempPath
+		}
+	}
+
 	// Run the Python script with configurable timeout
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	
-	cmd := exec.CommandContext(ctx, "python3", scriptPath, photoPath, "--output-json")
-	
+
+	cmd := exec.CommandContext(ctx, "python3", scriptPath, analysisPath, "--output-json")
+
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("OpenCV detection script failed: %v", err)
@@ -163,22 +262,27 @@ func analyzeWithOpenCV(photoPath string, timeout time.Duration) (*DetectionResul
 	return &result, nil
 }
 
-// analyzeWithEnhancedDetection performs the original enhanced detection as fallback
-func analyzeWithEnhancedDetection(photoPath string) (*DetectionResult, error) {
-	// Open and decode the image
-	file, err := os.Open(photoPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open photo: %v", err)
-	}
-	defer file.Close()
-
-	img, format, err := image.Decode(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %v", err)
-	}
+// analyzeWithEnhancedDetection performs the original enhanced detection as
+// fallback. When preprocessed is non-nil, it is analyzed directly instead of
+// re-decoding photoPath.
+func analyzeWithEnhancedDetection(photoPath string, preprocessed image.Image) (*DetectionResult, error) {
+	img := preprocessed
+	if img == nil {
+		// Open and decode the image
+		file, err := os.Open(photoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open photo: %v", err)
+		}
+		defer file.Close()
 
-	if format != "jpeg" && format != "png" && format != "gif" {
-		log.Printf("Warning: image format %s may not be fully supported, continuing with enhanced analysis", format)
+		decoded, format, err := image.Decode(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image: %v", err)
+		}
+		if format != "jpeg" && format != "png" && format != "gif" && format != "bmp" && format != "tiff" {
+			log.Printf("Warning: image format %s may not be fully supported, continuing with enhanced analysis", format)
+		}
+		img = decoded
 	}
 
 	// Initialize result
@@ -193,8 +297,12 @@ func analyzeWithEnhancedDetection(photoPath string) (*DetectionResult, error) {
 	// Analyze time of day using brightness
 	result.IsDay = analyzeTimeOfDayEnhanced(img)
 
+	// Build the shared integral-image planes once, and reuse them across
+	// every enhanced analyzer below instead of re-scanning the image.
+	frame := newIntegralFrame(img)
+
 	// Perform enhanced object detection
-	objects, details := detectObjectsEnhanced(img)
+	objects, details := detectObjectsEnhanced(frame)
 	result.Objects = objects
 	result.Details = details
 
@@ -259,36 +367,34 @@ func analyzeTimeOfDayEnhanced(img image.Image) bool {
 }
 
 // detectObjectsEnhanced performs sophisticated object detection using enhanced image analysis
-func detectObjectsEnhanced(img image.Image) ([]string, []ObjectDetail) {
-	bounds := img.Bounds()
-	width := bounds.Max.X - bounds.Min.X
-	height := bounds.Max.Y - bounds.Min.Y
+func detectObjectsEnhanced(frame *integralFrame) ([]string, []ObjectDetail) {
+	bounds := frame.bounds
 
-	if width == 0 || height == 0 {
+	if frame.width == 0 || frame.height == 0 {
 		return []string{"general scene"}, []ObjectDetail{}
 	}
 
 	objects := []string{}
 	details := []ObjectDetail{}
-	
+
 	// Enhanced analysis using multiple detection algorithms
 	results := make(map[string]float64)
-	
+
 	// Color pattern analysis
-	results = analyzeColorPatternsEnhanced(img, results)
-	
-	// Edge and texture analysis  
-	results = analyzeEdgesAndTextures(img, results)
-	
+	results = analyzeColorPatternsEnhanced(frame, results)
+
+	// Edge and texture analysis
+	results = analyzeEdgesAndTextures(frame, results)
+
 	// Shape and pattern analysis
-	results = analyzeShapesAndPatterns(img, results)
-	
+	results = analyzeShapesAndPatterns(frame, results)
+
 	// Motion/blur analysis (can indicate living objects)
-	results = analyzeMotionBlur(img, results)
-	
+	results = analyzeMotionBlur(frame, results)
+
 	// Convert results to objects and details
 	confidenceThreshold := 0.3
-	
+
 	for category, confidence := range results {
 		if confidence > confidenceThreshold {
 			detail := ObjectDetail{
@@ -298,7 +404,7 @@ func detectObjectsEnhanced(img image.Image) ([]string, []ObjectDetail) {
 				BBox:       generateFakeBBox(bounds), // Generate approximate bounding box for enhanced detection
 			}
 			details = append(details, detail)
-			
+
 			finalCategory := categorizeObject(category)
 			if !contains(objects, finalCategory) {
 				objects = append(objects, finalCategory)
@@ -313,11 +419,13 @@ func detectObjectsEnhanced(img image.Image) ([]string, []ObjectDetail) {
 	return objects, details
 }
 
-// analyzeColorPatternsEnhanced performs enhanced color pattern analysis
-func analyzeColorPatternsEnhanced(img image.Image, results map[string]float64) map[string]float64 {
-	bounds := img.Bounds()
+// analyzeColorPatternsEnhanced performs enhanced color pattern analysis,
+// classifying each sampleSize x sampleSize block by its mean color instead
+// of a single sampled pixel.
+func analyzeColorPatternsEnhanced(frame *integralFrame, results map[string]float64) map[string]float64 {
+	bounds := frame.bounds
 	sampleSize := 10 // Dense sampling for better accuracy
-	
+
 	// Color category counters
 	vegetation := 0
 	sky := 0
@@ -326,13 +434,15 @@ func analyzeColorPatternsEnhanced(img image.Image, results map[string]float64) m
 	skin := 0
 	metal := 0
 	totalSamples := 0
-	
+
 	for y := bounds.Min.Y; y < bounds.Max.Y; y += sampleSize {
 		for x := bounds.Min.X; x < bounds.Max.X; x += sampleSize {
-			r, g, b, _ := img.At(x, y).RGBA()
-			red := uint8(r >> 8)
-			green := uint8(g >> 8)
-			blue := uint8(b >> 8)
+			rMean, _ := frame.redMean(x, y, x+sampleSize, y+sampleSize)
+			gMean, _ := frame.greenMean(x, y, x+sampleSize, y+sampleSize)
+			bMean, _ := frame.blueMean(x, y, x+sampleSize, y+sampleSize)
+			red := uint8(rMean)
+			green := uint8(gMean)
+			blue := uint8(bMean)
 
 			// Enhanced color classification
 			if isVegetationColor(red, green, blue) {
@@ -384,31 +494,29 @@ func analyzeColorPatternsEnhanced(img image.Image, results map[string]float64) m
 }
 
 // analyzeEdgesAndTextures analyzes edges and textures to detect objects
-func analyzeEdgesAndTextures(img image.Image, results map[string]float64) map[string]float64 {
-	bounds := img.Bounds()
-	
+func analyzeEdgesAndTextures(frame *integralFrame, results map[string]float64) map[string]float64 {
+	bounds := frame.bounds
+
 	// Simple edge detection using brightness gradients
 	edgeCount := 0
 	highFreqVariation := 0.0
 	totalSamples := 0
 	sampleSize := 15
-	
-	for y := bounds.Min.Y + sampleSize; y < bounds.Max.Y - sampleSize; y += sampleSize {
-		for x := bounds.Min.X + sampleSize; x < bounds.Max.X - sampleSize; x += sampleSize {
+
+	for y := bounds.Min.Y + sampleSize; y < bounds.Max.Y-sampleSize; y += sampleSize {
+		for x := bounds.Min.X + sampleSize; x < bounds.Max.X-sampleSize; x += sampleSize {
 			// Get current pixel brightness
-			r, g, b, _ := img.At(x, y).RGBA()
-			brightness := 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
-			
+			brightness := frame.lumAt(x, y)
+
 			// Check gradients in 4 directions
 			directions := [][]int{{0, sampleSize}, {sampleSize, 0}, {0, -sampleSize}, {-sampleSize, 0}}
 			maxGradient := 0.0
 			totalGradient := 0.0
-			
+
 			for _, dir := range directions {
-				nx, ny := x + dir[0], y + dir[1]
+				nx, ny := x+dir[0], y+dir[1]
 				if nx >= bounds.Min.X && nx < bounds.Max.X && ny >= bounds.Min.Y && ny < bounds.Max.Y {
-					r2, g2, b2, _ := img.At(nx, ny).RGBA()
-					brightness2 := 0.299*float64(r2>>8) + 0.587*float64(g2>>8) + 0.114*float64(b2>>8)
+					brightness2 := frame.lumAt(nx, ny)
 					gradient := math.Abs(brightness - brightness2)
 					totalGradient += gradient
 					if gradient > maxGradient {
@@ -416,7 +524,7 @@ func analyzeEdgesAndTextures(img image.Image, results map[string]float64) map[st
 					}
 				}
 			}
-			
+
 			avgGradient := totalGradient / 4.0
 			if maxGradient > 30 {
 				edgeCount++
@@ -449,34 +557,34 @@ func analyzeEdgesAndTextures(img image.Image, results map[string]float64) map[st
 }
 
 // analyzeShapesAndPatterns analyzes geometric shapes and patterns
-func analyzeShapesAndPatterns(img image.Image, results map[string]float64) map[string]float64 {
-	bounds := img.Bounds()
-	
+func analyzeShapesAndPatterns(frame *integralFrame, results map[string]float64) map[string]float64 {
+	bounds := frame.bounds
+
 	// Look for regular patterns that might indicate artificial objects
 	horizontalLines := 0
 	verticalLines := 0
 	diagonalPatterns := 0
 	totalSamples := 0
-	
+
 	sampleSize := 20
 	lineThreshold := 5 // minimum consecutive similar pixels to count as a line
-	
-	for y := bounds.Min.Y; y < bounds.Max.Y - sampleSize*lineThreshold; y += sampleSize {
-		for x := bounds.Min.X; x < bounds.Max.X - sampleSize*lineThreshold; x += sampleSize {
+
+	for y := bounds.Min.Y; y < bounds.Max.Y-sampleSize*lineThreshold; y += sampleSize {
+		for x := bounds.Min.X; x < bounds.Max.X-sampleSize*lineThreshold; x += sampleSize {
 			// Check for horizontal lines
-			horizontalConsistency := checkLineConsistency(img, x, y, sampleSize, 0, lineThreshold)
+			horizontalConsistency := checkLineConsistency(frame, x, y, sampleSize, 0, lineThreshold)
 			if horizontalConsistency > 0.7 {
 				horizontalLines++
 			}
-			
+
 			// Check for vertical lines
-			verticalConsistency := checkLineConsistency(img, x, y, 0, sampleSize, lineThreshold)
+			verticalConsistency := checkLineConsistency(frame, x, y, 0, sampleSize, lineThreshold)
 			if verticalConsistency > 0.7 {
 				verticalLines++
 			}
-			
+
 			// Check for diagonal patterns
-			diagonalConsistency := checkLineConsistency(img, x, y, sampleSize, sampleSize, lineThreshold)
+			diagonalConsistency := checkLineConsistency(frame, x, y, sampleSize, sampleSize, lineThreshold)
 			if diagonalConsistency > 0.6 {
 				diagonalPatterns++
 			}
@@ -505,35 +613,29 @@ func analyzeShapesAndPatterns(img image.Image, results map[string]float64) map[s
 }
 
 // analyzeMotionBlur analyzes motion blur that might indicate moving objects
-func analyzeMotionBlur(img image.Image, results map[string]float64) map[string]float64 {
-	bounds := img.Bounds()
-	
+func analyzeMotionBlur(frame *integralFrame, results map[string]float64) map[string]float64 {
+	bounds := frame.bounds
+
 	// Detect motion blur by analyzing directional gradients
 	horizontalBlur := 0.0
 	verticalBlur := 0.0
 	totalSamples := 0
 	sampleSize := 25
-	
-	for y := bounds.Min.Y + sampleSize; y < bounds.Max.Y - sampleSize; y += sampleSize {
-		for x := bounds.Min.X + sampleSize; x < bounds.Max.X - sampleSize; x += sampleSize {
+
+	for y := bounds.Min.Y + sampleSize; y < bounds.Max.Y-sampleSize; y += sampleSize {
+		for x := bounds.Min.X + sampleSize; x < bounds.Max.X-sampleSize; x += sampleSize {
 			// Check horizontal blur (compare with pixels to left and right)
-			r1, g1, b1, _ := img.At(x-sampleSize, y).RGBA()
-			r2, g2, b2, _ := img.At(x+sampleSize, y).RGBA()
-			brightness1 := 0.299*float64(r1>>8) + 0.587*float64(g1>>8) + 0.114*float64(b1>>8)
-			brightness2 := 0.299*float64(r2>>8) + 0.587*float64(g2>>8) + 0.114*float64(b2>>8)
-			
+			brightness1 := frame.lumAt(x-sampleSize, y)
+			brightness2 := frame.lumAt(x+sampleSize, y)
 			hBlur := math.Abs(brightness1 - brightness2)
 			horizontalBlur += hBlur
-			
+
 			// Check vertical blur
-			r3, g3, b3, _ := img.At(x, y-sampleSize).RGBA()
-			r4, g4, b4, _ := img.At(x, y+sampleSize).RGBA()
-			brightness3 := 0.299*float64(r3>>8) + 0.587*float64(g3>>8) + 0.114*float64(b3>>8)
-			brightness4 := 0.299*float64(r4>>8) + 0.587*float64(g4>>8) + 0.114*float64(b4>>8)
-			
+			brightness3 := frame.lumAt(x, y-sampleSize)
+			brightness4 := frame.lumAt(x, y+sampleSize)
 			vBlur := math.Abs(brightness3 - brightness4)
 			verticalBlur += vBlur
-			
+
 			totalSamples++
 		}
 	}
@@ -556,35 +658,32 @@ func analyzeMotionBlur(img image.Image, results map[string]float64) map[string]f
 }
 
 // Helper function to check line consistency for pattern detection
-func checkLineConsistency(img image.Image, startX, startY, deltaX, deltaY, length int) float64 {
-	bounds := img.Bounds()
-	if startX + deltaX*length >= bounds.Max.X || startY + deltaY*length >= bounds.Max.Y {
+func checkLineConsistency(frame *integralFrame, startX, startY, deltaX, deltaY, length int) float64 {
+	bounds := frame.bounds
+	if startX+deltaX*length >= bounds.Max.X || startY+deltaY*length >= bounds.Max.Y {
 		return 0.0
 	}
-	
+
 	// Get the first pixel as reference
-	r1, g1, b1, _ := img.At(startX, startY).RGBA()
-	refBrightness := 0.299*float64(r1>>8) + 0.587*float64(g1>>8) + 0.114*float64(b1>>8)
-	
+	refBrightness := frame.lumAt(startX, startY)
+
 	consistentPixels := 1 // Count the first pixel
-	tolerance := 30.0 // Brightness tolerance for considering pixels similar
-	
+	tolerance := 30.0     // Brightness tolerance for considering pixels similar
+
 	for i := 1; i < length; i++ {
 		x := startX + deltaX*i
 		y := startY + deltaY*i
-		
+
 		if x >= bounds.Max.X || y >= bounds.Max.Y {
 			break
 		}
-		
-		r, g, b, _ := img.At(x, y).RGBA()
-		brightness := 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
-		
-		if math.Abs(brightness - refBrightness) < tolerance {
+
+		brightness := frame.lumAt(x, y)
+		if math.Abs(brightness-refBrightness) < tolerance {
 			consistentPixels++
 		}
 	}
-	
+
 	return float64(consistentPixels) / float64(length)
 }
 
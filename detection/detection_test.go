@@ -1,8 +1,10 @@
 package detection
 
 import (
+	"bytes"
 	"image"
 	"image/color"
+	"image/jpeg"
 	"testing"
 )
 
@@ -37,7 +39,7 @@ func TestAnalyzeTimeOfDayEnhanced(t *testing.T) {
 func TestDetectObjectsEnhanced(t *testing.T) {
 	// Create a green test image (vegetation)
 	greenImg := createTestImage(200, 200, color.RGBA{50, 150, 50, 255})
-	objects, details := detectObjectsEnhanced(greenImg)
+	objects, details := detectObjectsEnhanced(newIntegralFrame(greenImg))
 	
 	found := false
 	for _, obj := range objects {
@@ -148,7 +150,7 @@ func TestBoundingBoxGeneration(t *testing.T) {
 func TestDetectionWithBoundingBoxes(t *testing.T) {
 	// Create a green test image (should detect vegetation)
 	greenImg := createTestImage(300, 200, color.RGBA{50, 150, 50, 255})
-	objects, details := detectObjectsEnhanced(greenImg)
+	objects, details := detectObjectsEnhanced(newIntegralFrame(greenImg))
 	
 	// Check that details include bounding boxes
 	for _, detail := range details {
@@ -171,6 +173,29 @@ func TestDetectionWithBoundingBoxes(t *testing.T) {
 	}
 }
 
+func TestAnalyzeReader(t *testing.T) {
+	img := createTestImage(64, 64, color.RGBA{50, 150, 50, 255})
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("failed to encode test JPEG: %v", err)
+	}
+
+	result, err := AnalyzeReader(&buf, "jpeg")
+	if err != nil {
+		t.Fatalf("AnalyzeReader returned an error: %v", err)
+	}
+	if len(result.Objects) == 0 {
+		t.Error("Expected AnalyzeReader to detect at least one object")
+	}
+}
+
+func TestAnalyzeReader_InvalidData(t *testing.T) {
+	if _, err := AnalyzeReader(bytes.NewReader([]byte("not an image")), "jpeg"); err == nil {
+		t.Error("Expected an error for undecodable data")
+	}
+}
+
 // Helper function to create test images
 func createTestImage(width, height int, c color.RGBA) image.Image {
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -0,0 +1,133 @@
+package detection
+
+import (
+	"image"
+	"math"
+)
+
+// integralFrame precomputes luminance and per-channel RGB planes, plus their
+// summed-area tables (and a squared-luminance table for variance, mirroring
+// SummedAreaTable in cascade.go), in a single pass over an image. Every
+// enhanced analyzer below is built against integralFrame instead of the raw
+// image.Image, since repeatedly calling img.At(x, y).RGBA() - which the
+// analyzers used to do hundreds of times per photo - is the slowest path in
+// the image package: once a frame is built, any rectangular region's mean
+// brightness, per-channel average, or standard deviation is an O(1)
+// four-corner lookup.
+type integralFrame struct {
+	bounds        image.Rectangle
+	width, height int
+
+	lum, red, green, blue []float64 // flat, row-major planes, one value per pixel
+
+	lumSum, lumSqSum, redSum, greenSum, blueSum [][]float64 // (width+1) x (height+1) summed-area tables
+}
+
+// newIntegralFrame walks img once, building its luminance/RGB planes and
+// summed-area tables.
+func newIntegralFrame(img image.Image) *integralFrame {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	f := &integralFrame{
+		bounds:   bounds,
+		width:    width,
+		height:   height,
+		lum:      make([]float64, width*height),
+		red:      make([]float64, width*height),
+		green:    make([]float64, width*height),
+		blue:     make([]float64, width*height),
+		lumSum:   newFloatTable(width, height),
+		lumSqSum: newFloatTable(width, height),
+		redSum:   newFloatTable(width, height),
+		greenSum: newFloatTable(width, height),
+		blueSum:  newFloatTable(width, height),
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			red, green, blue := float64(r>>8), float64(g>>8), float64(b>>8)
+			lum := 0.299*red + 0.587*green + 0.114*blue
+
+			idx := y*width + x
+			f.lum[idx], f.red[idx], f.green[idx], f.blue[idx] = lum, red, green, blue
+
+			f.lumSum[y+1][x+1] = lum + f.lumSum[y][x+1] + f.lumSum[y+1][x] - f.lumSum[y][x]
+			f.lumSqSum[y+1][x+1] = lum*lum + f.lumSqSum[y][x+1] + f.lumSqSum[y+1][x] - f.lumSqSum[y][x]
+			f.redSum[y+1][x+1] = red + f.redSum[y][x+1] + f.redSum[y+1][x] - f.redSum[y][x]
+			f.greenSum[y+1][x+1] = green + f.greenSum[y][x+1] + f.greenSum[y+1][x] - f.greenSum[y][x]
+			f.blueSum[y+1][x+1] = blue + f.blueSum[y][x+1] + f.blueSum[y+1][x] - f.blueSum[y][x]
+		}
+	}
+
+	return f
+}
+
+func newFloatTable(width, height int) [][]float64 {
+	t := make([][]float64, height+1)
+	for y := range t {
+		t[y] = make([]float64, width+1)
+	}
+	return t
+}
+
+// lumAt returns the luminance at absolute image coordinates (x, y), or 0 if
+// (x, y) is outside the frame's bounds. This replaces single-pixel
+// img.At(x, y).RGBA() reads with a flat-array lookup.
+func (f *integralFrame) lumAt(x, y int) float64 {
+	lx, ly := x-f.bounds.Min.X, y-f.bounds.Min.Y
+	if lx < 0 || lx >= f.width || ly < 0 || ly >= f.height {
+		return 0
+	}
+	return f.lum[ly*f.width+lx]
+}
+
+// blockMean returns the mean of table over the absolute-coordinate
+// rectangle [x0,x1) x [y0,y1), clamped to the frame's bounds. ok is false if
+// the clamped region is empty.
+func (f *integralFrame) blockMean(table [][]float64, x0, y0, x1, y1 int) (mean float64, ok bool) {
+	lx0, ly0 := clampInt(x0-f.bounds.Min.X, 0, f.width), clampInt(y0-f.bounds.Min.Y, 0, f.height)
+	lx1, ly1 := clampInt(x1-f.bounds.Min.X, 0, f.width), clampInt(y1-f.bounds.Min.Y, 0, f.height)
+	area := (lx1 - lx0) * (ly1 - ly0)
+	if area <= 0 {
+		return 0, false
+	}
+	sum := table[ly1][lx1] - table[ly0][lx1] - table[ly1][lx0] + table[ly0][lx0]
+	return sum / float64(area), true
+}
+
+// lumMean returns the mean luminance over [x0,x1) x [y0,y1).
+func (f *integralFrame) lumMean(x0, y0, x1, y1 int) (float64, bool) {
+	return f.blockMean(f.lumSum, x0, y0, x1, y1)
+}
+
+// lumStdDev returns the luminance standard deviation over [x0,x1) x
+// [y0,y1), the same way cascade.go's SummedAreaTable.WindowMeanStdDev uses
+// its squared table.
+func (f *integralFrame) lumStdDev(x0, y0, x1, y1 int) (float64, bool) {
+	mean, ok := f.lumMean(x0, y0, x1, y1)
+	if !ok {
+		return 0, false
+	}
+	sqMean, _ := f.blockMean(f.lumSqSum, x0, y0, x1, y1)
+	variance := sqMean - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return math.Sqrt(variance), true
+}
+
+// redMean, greenMean, and blueMean return the mean of the respective color
+// channel over [x0,x1) x [y0,y1).
+func (f *integralFrame) redMean(x0, y0, x1, y1 int) (float64, bool) {
+	return f.blockMean(f.redSum, x0, y0, x1, y1)
+}
+
+func (f *integralFrame) greenMean(x0, y0, x1, y1 int) (float64, bool) {
+	return f.blockMean(f.greenSum, x0, y0, x1, y1)
+}
+
+func (f *integralFrame) blueMean(x0, y0, x1, y1 int) (float64, bool) {
+	return f.blockMean(f.blueSum, x0, y0, x1, y1)
+}
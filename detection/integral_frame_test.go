@@ -0,0 +1,97 @@
+package detection
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestIntegralFrameLumAt(t *testing.T) {
+	img := createTestImage(10, 10, color.RGBA{60, 150, 30, 255})
+	frame := newIntegralFrame(img)
+
+	expected := 0.299*60 + 0.587*150 + 0.114*30
+	if got := frame.lumAt(0, 0); got != expected {
+		t.Errorf("lumAt(0,0) = %v, expected %v", got, expected)
+	}
+	if got := frame.lumAt(9, 9); got != expected {
+		t.Errorf("lumAt(9,9) = %v, expected %v", got, expected)
+	}
+	if got := frame.lumAt(10, 0); got != 0 {
+		t.Errorf("lumAt out of bounds = %v, expected 0", got)
+	}
+}
+
+func TestIntegralFrameBlockMeans(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if x < 2 {
+				img.Set(x, y, color.RGBA{0, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.RGBA{200, 100, 50, 255})
+			}
+		}
+	}
+	frame := newIntegralFrame(img)
+
+	if mean, ok := frame.redMean(0, 0, 2, 4); !ok || mean != 0 {
+		t.Errorf("left-half redMean = %v (ok=%v), expected 0", mean, ok)
+	}
+	if mean, ok := frame.redMean(2, 0, 4, 4); !ok || mean != 200 {
+		t.Errorf("right-half redMean = %v (ok=%v), expected 200", mean, ok)
+	}
+	if mean, ok := frame.greenMean(0, 0, 4, 4); !ok || mean != 50 {
+		t.Errorf("full-width greenMean = %v (ok=%v), expected 50", mean, ok)
+	}
+	if mean, ok := frame.blueMean(0, 0, 0, 0); ok || mean != 0 {
+		t.Errorf("empty-region blueMean = %v (ok=%v), expected ok=false", mean, ok)
+	}
+}
+
+func TestIntegralFrameLumStdDev(t *testing.T) {
+	img := createTestImage(8, 8, color.RGBA{128, 128, 128, 255})
+	frame := newIntegralFrame(img)
+
+	if stddev, ok := frame.lumStdDev(0, 0, 8, 8); !ok || stddev != 0 {
+		t.Errorf("uniform image lumStdDev = %v (ok=%v), expected 0", stddev, ok)
+	}
+
+	mixed := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if x < 2 {
+				mixed.Set(x, y, color.RGBA{0, 0, 0, 255})
+			} else {
+				mixed.Set(x, y, color.RGBA{255, 255, 255, 255})
+			}
+		}
+	}
+	mixedFrame := newIntegralFrame(mixed)
+	if stddev, ok := mixedFrame.lumStdDev(0, 0, 4, 4); !ok || stddev <= 0 {
+		t.Errorf("mixed image lumStdDev = %v (ok=%v), expected > 0", stddev, ok)
+	}
+}
+
+// BenchmarkAnalyzeWithEnhancedDetectionPasses measures the combined cost of
+// the four enhanced-detection passes against a shared integralFrame, to
+// track the speedup from replacing per-pixel img.At(x, y).RGBA() calls with
+// O(1) summed-area-table lookups.
+func BenchmarkAnalyzeWithEnhancedDetectionPasses(b *testing.B) {
+	img := image.NewRGBA(image.Rect(0, 0, 640, 480))
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			img.Set(x, y, color.RGBA{uint8(x % 255), uint8(y % 255), uint8((x + y) % 255), 255})
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		frame := newIntegralFrame(img)
+		results := make(map[string]float64)
+		results = analyzeColorPatternsEnhanced(frame, results)
+		results = analyzeEdgesAndTextures(frame, results)
+		results = analyzeShapesAndPatterns(frame, results)
+		_ = analyzeMotionBlur(frame, results)
+	}
+}
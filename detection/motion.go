@@ -0,0 +1,142 @@
+package detection
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg" // Register JPEG format
+	_ "image/png"  // Register PNG format
+	"os"
+)
+
+// MotionConfig controls the integral-image, grid-based frame differencing
+// performed by DetectMotion.
+type MotionConfig struct {
+	GridCols           int     // number of grid cells across the frame width
+	GridRows           int     // number of grid cells across the frame height
+	CellDeltaThreshold float64 // minimum mean-intensity delta (0-255) for a cell to count as "changed"
+	ChangedCellRatio   float64 // fraction of changed cells (0-1) required to declare motion
+}
+
+// MotionResult is the outcome of comparing two frames with DetectMotion.
+type MotionResult struct {
+	Motion       bool
+	ChangedCells int
+	TotalCells   int
+	ChangedRatio float64
+}
+
+// DetectMotion compares the grayscale versions of previousPath and
+// currentPath using grid-based, integral-image frame differencing: each
+// frame is summed into an integral image (summed-area table) in a single
+// pass, so the mean intensity of any grid cell can then be computed in O(1)
+// via the four-corner formula regardless of cell size. Motion is declared
+// when the fraction of cells whose mean intensity changed by more than
+// config.CellDeltaThreshold exceeds config.ChangedCellRatio.
+func DetectMotion(previousPath, currentPath string, config MotionConfig) (*MotionResult, error) {
+	previous, err := loadGrayscale(previousPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load previous frame: %v", err)
+	}
+	current, err := loadGrayscale(currentPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current frame: %v", err)
+	}
+	if previous.Bounds() != current.Bounds() {
+		return nil, fmt.Errorf("frame size mismatch: previous %v vs current %v", previous.Bounds(), current.Bounds())
+	}
+
+	cols, rows := config.GridCols, config.GridRows
+	if cols <= 0 {
+		cols = 16
+	}
+	if rows <= 0 {
+		rows = 12
+	}
+
+	prevIntegral := integralImage(previous)
+	currIntegral := integralImage(current)
+
+	bounds := current.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	changedCells := 0
+	totalCells := cols * rows
+	for row := 0; row < rows; row++ {
+		y0 := row * height / rows
+		y1 := (row + 1) * height / rows
+		for col := 0; col < cols; col++ {
+			x0 := col * width / cols
+			x1 := (col + 1) * width / cols
+
+			delta := cellMean(prevIntegral, x0, y0, x1, y1) - cellMean(currIntegral, x0, y0, x1, y1)
+			if delta < 0 {
+				delta = -delta
+			}
+			if delta > config.CellDeltaThreshold {
+				changedCells++
+			}
+		}
+	}
+
+	ratio := float64(changedCells) / float64(totalCells)
+	return &MotionResult{
+		Motion:       ratio > config.ChangedCellRatio,
+		ChangedCells: changedCells,
+		TotalCells:   totalCells,
+		ChangedRatio: ratio,
+	}, nil
+}
+
+// loadGrayscale decodes the image at path and converts it to grayscale.
+func loadGrayscale(path string) (*image.Gray, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	gray := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray.Set(x, y, img.At(x, y))
+		}
+	}
+	return gray, nil
+}
+
+// integralImage computes a summed-area table of img in a single pass. The
+// table is padded by one leading row/column of zeros (sum[0][*] and
+// sum[*][0]) so cellMean's four-corner lookups never need bounds checks.
+func integralImage(img *image.Gray) [][]uint64 {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	sum := make([][]uint64, height+1)
+	for y := range sum {
+		sum[y] = make([]uint64, width+1)
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixel := uint64(img.GrayAt(bounds.Min.X+x, bounds.Min.Y+y).Y)
+			sum[y+1][x+1] = pixel + sum[y][x+1] + sum[y+1][x] - sum[y][x]
+		}
+	}
+	return sum
+}
+
+// cellMean returns the mean pixel intensity within the [x0,x1) x [y0,y1)
+// rectangle of integral, computed in O(1) via the four-corner formula.
+func cellMean(integral [][]uint64, x0, y0, x1, y1 int) float64 {
+	area := (x1 - x0) * (y1 - y0)
+	if area <= 0 {
+		return 0
+	}
+	total := integral[y1][x1] - integral[y0][x1] - integral[y1][x0] + integral[y0][x0]
+	return float64(total) / float64(area)
+}
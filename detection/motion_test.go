@@ -0,0 +1,105 @@
+package detection
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIntegralImageCellMean(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetGray(x, y, color.Gray{Y: 100})
+		}
+	}
+
+	integral := integralImage(img)
+	mean := cellMean(integral, 0, 0, 4, 4)
+	if mean != 100 {
+		t.Errorf("Expected mean of 100, got %v", mean)
+	}
+
+	quadrantMean := cellMean(integral, 0, 0, 2, 2)
+	if quadrantMean != 100 {
+		t.Errorf("Expected quadrant mean of 100, got %v", quadrantMean)
+	}
+}
+
+func TestDetectMotionNoChange(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestJPEG(t, dir, "a.jpg", color.RGBA{100, 100, 100, 255})
+	b := writeTestJPEG(t, dir, "b.jpg", color.RGBA{100, 100, 100, 255})
+
+	result, err := DetectMotion(a, b, MotionConfig{GridCols: 4, GridRows: 4, CellDeltaThreshold: 10, ChangedCellRatio: 0.05})
+	if err != nil {
+		t.Fatalf("DetectMotion returned an error: %v", err)
+	}
+	if result.Motion {
+		t.Error("Expected no motion between two identical frames")
+	}
+}
+
+func TestDetectMotionChangedHalf(t *testing.T) {
+	dir := t.TempDir()
+	previous := writeTestJPEG(t, dir, "previous.jpg", color.RGBA{20, 20, 20, 255})
+	current := writeHalfChangedTestJPEG(t, dir, "current.jpg", color.RGBA{20, 20, 20, 255}, color.RGBA{220, 220, 220, 255})
+
+	result, err := DetectMotion(previous, current, MotionConfig{GridCols: 4, GridRows: 4, CellDeltaThreshold: 10, ChangedCellRatio: 0.05})
+	if err != nil {
+		t.Fatalf("DetectMotion returned an error: %v", err)
+	}
+	if !result.Motion {
+		t.Error("Expected motion when half the frame changed drastically")
+	}
+	if result.ChangedCells == 0 {
+		t.Error("Expected at least one changed cell")
+	}
+}
+
+func TestDetectMotionMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	existing := writeTestJPEG(t, dir, "a.jpg", color.RGBA{10, 10, 10, 255})
+
+	if _, err := DetectMotion(existing, filepath.Join(dir, "missing.jpg"), MotionConfig{}); err == nil {
+		t.Error("Expected an error for a missing current frame")
+	}
+}
+
+func writeTestJPEG(t *testing.T, dir, name string, c color.RGBA) string {
+	t.Helper()
+	img := createTestImage(64, 64, c)
+	return encodeJPEG(t, dir, name, img)
+}
+
+func writeHalfChangedTestJPEG(t *testing.T, dir, name string, left, right color.RGBA) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if x < 32 {
+				img.Set(x, y, left)
+			} else {
+				img.Set(x, y, right)
+			}
+		}
+	}
+	return encodeJPEG(t, dir, name, img)
+}
+
+func encodeJPEG(t *testing.T, dir, name string, img image.Image) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create test JPEG: %v", err)
+	}
+	defer f.Close()
+	if err := jpeg.Encode(f, img, nil); err != nil {
+		t.Fatalf("failed to encode test JPEG: %v", err)
+	}
+	return path
+}
@@ -16,11 +16,41 @@ import (
 	"gocv.io/x/gocv"
 )
 
+// ModelType identifies which network format a loaded model uses, since the
+// output tensor layout (and therefore post-processing) differs between them.
+type ModelType int
+
+const (
+	// ModelTypeDarknet covers YOLOv3/v4 .weights+.cfg pairs loaded via gocv.ReadNetFromDarknet.
+	ModelTypeDarknet ModelType = iota
+	// ModelTypeONNX covers YOLOv8 .onnx exports loaded via gocv.ReadNetFromONNX.
+	ModelTypeONNX
+)
+
+// defaultInputSize is used when DetectorOptions.InputSize is left at zero.
+const defaultInputSize = 416
+
+// DetectorOptions configures model selection and detection thresholds.
+// ModelPath/ConfigPath/ClassesPath may be left empty to fall back to the
+// legacy hardcoded search under /opt/yolo/.
+type DetectorOptions struct {
+	ModelPath           string // path to .weights (Darknet) or .onnx (YOLOv8) file
+	ConfigPath          string // path to Darknet .cfg file; ignored for ONNX models
+	ClassesPath         string // path to a newline-separated class names file
+	InputSize           int    // width/height of the square blob fed into the network
+	ConfidenceThreshold float32
+	NMSThreshold        float32
+}
+
 // OpenCVDetector provides native Go OpenCV object detection capabilities
 type OpenCVDetector struct {
-	net         *gocv.Net
-	classes     []string
-	initialized bool
+	net                 *gocv.Net
+	classes             []string
+	initialized         bool
+	modelType           ModelType
+	inputSize           int
+	confidenceThreshold float32
+	nmsThreshold        float32
 }
 
 // DetectionBox represents a detected object with its bounding box and confidence
@@ -35,14 +65,54 @@ type DetectionBox struct {
 }
 
 // NewOpenCVDetector creates a new OpenCV detector instance
-func NewOpenCVDetector() *OpenCVDetector {
-	detector := &OpenCVDetector{}
-	detector.initialize()
+func NewOpenCVDetector(opts DetectorOptions) *OpenCVDetector {
+	detector := &OpenCVDetector{
+		inputSize:           defaultInputSize,
+		confidenceThreshold: 0.5,
+		nmsThreshold:        0.4,
+	}
+	if opts.InputSize > 0 {
+		detector.inputSize = opts.InputSize
+	}
+	if opts.ConfidenceThreshold > 0 {
+		detector.confidenceThreshold = opts.ConfidenceThreshold
+	}
+	if opts.NMSThreshold > 0 {
+		detector.nmsThreshold = opts.NMSThreshold
+	}
+	detector.initialize(opts)
 	return detector
 }
 
-// initialize sets up the YOLO detector with pre-trained weights
-func (d *OpenCVDetector) initialize() {
+// initialize sets up the YOLO detector with pre-trained weights. When
+// opts.ModelPath is set, that model is loaded directly (the file extension
+// selects Darknet vs. ONNX). Otherwise it falls back to probing the legacy
+// set of well-known Darknet model locations under /opt/yolo/.
+func (d *OpenCVDetector) initialize(opts DetectorOptions) {
+	classesPath := opts.ClassesPath
+	if classesPath == "" {
+		classesPath = "/opt/yolo/coco.names"
+	}
+
+	// Load class names
+	if err := d.loadClassNames(classesPath); err != nil {
+		log.Printf("Failed to load class names from %s: %v", classesPath, err)
+		// Use default COCO class names as fallback
+		d.classes = cocoClassNames
+	}
+
+	if opts.ModelPath != "" {
+		if strings.EqualFold(filepath.Ext(opts.ModelPath), ".onnx") {
+			d.initialized = d.tryLoadONNXModel(opts.ModelPath, "YOLOv8-ONNX")
+		} else {
+			d.initialized = d.tryLoadModel(opts.ModelPath, opts.ConfigPath, "configured Darknet model")
+		}
+		if !d.initialized {
+			log.Printf("Warning: failed to load configured model %s. OpenCV detection will not be available.", opts.ModelPath)
+		}
+		return
+	}
+
 	// Try to load YOLO model files in order of preference
 	modelPaths := []struct {
 		weights string
@@ -54,15 +124,6 @@ func (d *OpenCVDetector) initialize() {
 		{"/opt/yolo/yolov4-tiny.weights", "/opt/yolo/yolov4-tiny.cfg", "YOLOv4-tiny"},
 	}
 
-	classesPath := "/opt/yolo/coco.names"
-
-	// Load class names
-	if err := d.loadClassNames(classesPath); err != nil {
-		log.Printf("Failed to load class names from %s: %v", classesPath, err)
-		// Use default COCO class names as fallback
-		d.classes = cocoClassNames
-	}
-
 	// Try each model configuration
 	for _, model := range modelPaths {
 		if d.tryLoadModel(model.weights, model.config, model.name) {
@@ -75,7 +136,7 @@ func (d *OpenCVDetector) initialize() {
 	d.initialized = false
 }
 
-// tryLoadModel attempts to load a specific YOLO model
+// tryLoadModel attempts to load a Darknet (.weights+.cfg) YOLO model
 func (d *OpenCVDetector) tryLoadModel(weightsPath, configPath, modelName string) bool {
 	// Check if both files exist
 	if _, err := os.Stat(weightsPath); os.IsNotExist(err) {
@@ -97,6 +158,28 @@ func (d *OpenCVDetector) tryLoadModel(weightsPath, configPath, modelName string)
 	net.SetPreferableTarget(gocv.NetTargetCPU)
 
 	d.net = &net
+	d.modelType = ModelTypeDarknet
+	log.Printf("Successfully loaded %s model for object detection", modelName)
+	return true
+}
+
+// tryLoadONNXModel attempts to load a YOLOv8 ONNX model
+func (d *OpenCVDetector) tryLoadONNXModel(modelPath, modelName string) bool {
+	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
+		return false
+	}
+
+	net := gocv.ReadNetFromONNX(modelPath)
+	if net.Empty() {
+		log.Printf("Failed to load %s model from %s", modelName, modelPath)
+		return false
+	}
+
+	net.SetPreferableBackend(gocv.NetBackendOpenCV)
+	net.SetPreferableTarget(gocv.NetTargetCPU)
+
+	d.net = &net
+	d.modelType = ModelTypeONNX
 	log.Printf("Successfully loaded %s model for object detection", modelName)
 	return true
 }
@@ -132,7 +215,7 @@ func (d *OpenCVDetector) DetectObjects(imagePath string) ([]DetectionBox, error)
 // detectObjectsFromMat performs detection on a gocv.Mat
 func (d *OpenCVDetector) detectObjectsFromMat(img gocv.Mat) ([]DetectionBox, error) {
 	// Create blob from image
-	blob := gocv.BlobFromImage(img, 1.0/255.0, image.Pt(416, 416), gocv.NewScalar(0, 0, 0, 0), true, false, gocv.MatTypeCV32F)
+	blob := gocv.BlobFromImage(img, 1.0/255.0, image.Pt(d.inputSize, d.inputSize), gocv.NewScalar(0, 0, 0, 0), true, false, gocv.MatTypeCV32F)
 	defer blob.Close()
 
 	// Set input to the network
@@ -153,15 +236,25 @@ func (d *OpenCVDetector) detectObjectsFromMat(img gocv.Mat) ([]DetectionBox, err
 	detections := d.processDetections(outputs, img.Cols(), img.Rows())
 
 	// Apply Non-Maximum Suppression (NMS)
-	filteredDetections := d.applyNMS(detections, 0.5, 0.4)
+	filteredDetections := d.applyNMS(detections, d.confidenceThreshold, d.nmsThreshold)
 
 	return filteredDetections, nil
 }
 
-// processDetections extracts detection boxes from network outputs
+// processDetections extracts detection boxes from network outputs, dispatching
+// to the layout appropriate for the loaded model type.
 func (d *OpenCVDetector) processDetections(outputs []gocv.Mat, imgWidth, imgHeight int) []DetectionBox {
+	if d.modelType == ModelTypeONNX {
+		return d.processONNXDetections(outputs, imgWidth, imgHeight)
+	}
+	return d.processDarknetDetections(outputs, imgWidth, imgHeight)
+}
+
+// processDarknetDetections extracts detection boxes from YOLOv3/v4 Darknet
+// outputs, where each row is [center_x, center_y, width, height, objectness, class_probs...].
+func (d *OpenCVDetector) processDarknetDetections(outputs []gocv.Mat, imgWidth, imgHeight int) []DetectionBox {
 	var detections []DetectionBox
-	confidenceThreshold := float32(0.5)
+	confidenceThreshold := d.confidenceThreshold
 
 	for _, output := range outputs {
 		data, err := output.DataPtrFloat32()
@@ -234,6 +327,80 @@ func (d *OpenCVDetector) processDetections(outputs []gocv.Mat, imgWidth, imgHeig
 	return detections
 }
 
+// processONNXDetections extracts detection boxes from a YOLOv8 ONNX output.
+// Unlike Darknet, YOLOv8 emits a single transposed tensor shaped [1, 84, 8400]
+// for COCO: row 0-3 hold cx,cy,w,h (shared across all 8400 candidate boxes)
+// and rows 4-83 hold per-class scores with no separate objectness score, so
+// confidence is simply the highest class score for each candidate.
+func (d *OpenCVDetector) processONNXDetections(outputs []gocv.Mat, imgWidth, imgHeight int) []DetectionBox {
+	var detections []DetectionBox
+	confidenceThreshold := d.confidenceThreshold
+
+	for _, output := range outputs {
+		data, err := output.DataPtrFloat32()
+		if err != nil {
+			continue
+		}
+
+		// output is [1, numAttributes, numBoxes] flattened row-major: rows are
+		// attributes (cx,cy,w,h,class0..classN), columns are candidate boxes.
+		numAttributes := output.Size()[1]
+		numBoxes := output.Size()[2]
+		numClasses := numAttributes - 4
+
+		for box := 0; box < numBoxes; box++ {
+			maxClassScore := float32(0)
+			classID := -1
+			for c := 0; c < numClasses; c++ {
+				score := data[(4+c)*numBoxes+box]
+				if score > maxClassScore {
+					maxClassScore = score
+					classID = c
+				}
+			}
+
+			if maxClassScore <= confidenceThreshold || classID < 0 || classID >= len(d.classes) {
+				continue
+			}
+
+			cx := data[0*numBoxes+box] * float32(imgWidth) / float32(d.inputSize)
+			cy := data[1*numBoxes+box] * float32(imgHeight) / float32(d.inputSize)
+			w := data[2*numBoxes+box] * float32(imgWidth) / float32(d.inputSize)
+			h := data[3*numBoxes+box] * float32(imgHeight) / float32(d.inputSize)
+
+			x := int(cx - w/2)
+			y := int(cy - h/2)
+			width := int(w)
+			height := int(h)
+
+			if x < 0 {
+				x = 0
+			}
+			if y < 0 {
+				y = 0
+			}
+			if x+width > imgWidth {
+				width = imgWidth - x
+			}
+			if y+height > imgHeight {
+				height = imgHeight - y
+			}
+
+			detections = append(detections, DetectionBox{
+				ClassID:    classID,
+				Class:      d.classes[classID],
+				Confidence: maxClassScore,
+				X:          x,
+				Y:          y,
+				Width:      width,
+				Height:     height,
+			})
+		}
+	}
+
+	return detections
+}
+
 // applyNMS applies Non-Maximum Suppression to remove overlapping detections
 func (d *OpenCVDetector) applyNMS(detections []DetectionBox, scoreThreshold, nmsThreshold float32) []DetectionBox {
 	if len(detections) == 0 {
@@ -318,8 +485,8 @@ func (d *OpenCVDetector) Close() {
 }
 
 // analyzeWithNativeOpenCV performs object detection using native Go OpenCV bindings
-func analyzeWithNativeOpenCV(photoPath string, timeout_unused interface{}) (*DetectionResult, error) {
-	detector := NewOpenCVDetector()
+func analyzeWithNativeOpenCV(photoPath string, config *DetectionConfig) (*DetectionResult, error) {
+	detector := NewOpenCVDetector(detectorOptionsFromConfig(config))
 	defer detector.Close()
 
 	if !detector.IsInitialized() {
@@ -389,8 +556,25 @@ func analyzeWithNativeOpenCV(photoPath string, timeout_unused interface{}) (*Det
 	// Generate summary
 	result.Summary = generateSummary(result.IsDay, result.Objects)
 
-	log.Printf("Native OpenCV detection completed: found %d objects in %d categories", 
+	log.Printf("Native OpenCV detection completed: found %d objects in %d categories",
 		len(result.Details), len(result.Objects))
 
 	return result, nil
+}
+
+// detectorOptionsFromConfig translates the REST-facing DetectionConfig into
+// the OpenCVDetector's options, falling back to the legacy /opt/yolo/ search
+// when no model has been configured.
+func detectorOptionsFromConfig(config *DetectionConfig) DetectorOptions {
+	if config == nil {
+		return DetectorOptions{}
+	}
+	return DetectorOptions{
+		ModelPath:           config.ModelPath,
+		ConfigPath:          config.ModelConfigPath,
+		ClassesPath:         config.ModelClassesPath,
+		InputSize:           config.InputSize,
+		ConfidenceThreshold: config.ConfidenceThreshold,
+		NMSThreshold:        config.NMSThreshold,
+	}
 }
\ No newline at end of file
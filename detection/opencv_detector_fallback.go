@@ -4,12 +4,116 @@
 package detection
 
 import (
+	"context"
 	"fmt"
+	"image"
+	_ "image/jpeg" // Register JPEG format
+	_ "image/png"  // Register PNG format
 	"log"
+	"os"
+	"time"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
 )
 
-// analyzeWithNativeOpenCV provides a fallback when OpenCV is not available
-func analyzeWithNativeOpenCV(photoPath string, timeout_unused interface{}) (*DetectionResult, error) {
-	log.Printf("Native OpenCV not available (not compiled with opencv build tag), falling back to enhanced detection")
-	return nil, fmt.Errorf("OpenCV not available - compile with -tags opencv to enable native Go OpenCV support")
-}
\ No newline at end of file
+// analyzeWithNativeOpenCV provides a dependency-free fallback when this
+// binary isn't built with the opencv tag (see opencv_detector.go): instead
+// of erroring out, it Sauvola-binarizes the photo (see sauvola.go) and
+// diffs it against the last binarization stored for config.Camera, so
+// low-light/outdoor scenes - clouds, mixed shadow - where a single global
+// threshold misses everything still trigger a "something changed" result
+// without bringing in gocv.
+func analyzeWithNativeOpenCV(photoPath string, config *DetectionConfig) (*DetectionResult, error) {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	frame, err := decodeIntegralFrameWithTimeout(ctx, photoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mask := sauvolaBinarize(frame, SauvolaConfig{})
+	bitmap := packBits(downsampleMask(mask, frame.width, frame.height, referenceBitmapSize))
+
+	prevRef, hadReference, err := conf.LoadDetectionReference(config.Camera)
+	if err != nil {
+		log.Printf("Failed to load Sauvola reference frame (%v), treating this as the first frame", err)
+		hadReference = false
+	}
+
+	changeRatio := 0.0
+	if hadReference {
+		totalBits := referenceBitmapSize * referenceBitmapSize
+		changeRatio = float64(hammingDistance(bitmap, prevRef.Bitmap)) / float64(totalBits)
+	}
+
+	if err := conf.StoreDetectionReference(config.Camera, conf.DetectionReference{Bitmap: bitmap}); err != nil {
+		log.Printf("Failed to persist Sauvola reference frame (%v)", err)
+	}
+
+	result := &DetectionResult{
+		PhotoPath: photoPath,
+		Objects:   []string{},
+		Details:   []ObjectDetail{},
+	}
+
+	meanLum, _ := frame.lumMean(frame.bounds.Min.X, frame.bounds.Min.Y, frame.bounds.Max.X, frame.bounds.Max.Y)
+	result.IsDay = meanLum > 70
+
+	if !hadReference {
+		result.Summary = "Sauvola change detection: no prior reference frame, establishing baseline"
+		return result, nil
+	}
+
+	result.Summary = fmt.Sprintf("Sauvola change detection: %.1f%% of the reference bitmap changed", changeRatio*100)
+
+	if bbox, area := largestForegroundBlob(mask, frame.width, frame.height); area > 0 {
+		result.Objects = append(result.Objects, "change")
+		result.Details = append(result.Details, ObjectDetail{
+			Class:      "change",
+			Confidence: float32(changeRatio),
+			Category:   "change",
+			BBox:       bbox,
+		})
+	}
+
+	return result, nil
+}
+
+// decodeIntegralFrameWithTimeout decodes photoPath and builds its
+// integralFrame on a background goroutine, returning ctx.Err() if ctx
+// expires first.
+func decodeIntegralFrameWithTimeout(ctx context.Context, photoPath string) (*integralFrame, error) {
+	type result struct {
+		frame *integralFrame
+		err   error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		file, err := os.Open(photoPath)
+		if err != nil {
+			done <- result{err: fmt.Errorf("failed to open photo: %v", err)}
+			return
+		}
+		defer file.Close()
+
+		img, _, err := image.Decode(file)
+		if err != nil {
+			done <- result{err: fmt.Errorf("failed to decode image: %v", err)}
+			return
+		}
+		done <- result{frame: newIntegralFrame(img)}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("Sauvola detection timed out: %v", ctx.Err())
+	case res := <-done:
+		return res.frame, res.err
+	}
+}
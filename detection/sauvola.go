@@ -0,0 +1,257 @@
+package detection
+
+// SauvolaConfig controls the pure-Go Sauvola adaptive-thresholding change
+// detector used by analyzeWithNativeOpenCV's !opencv fallback (see
+// opencv_detector_fallback.go). Zero values fall back to the defaults below.
+type SauvolaConfig struct {
+	WindowSize int     // side length (pixels) of the local mean/stddev window
+	K          float64 // sensitivity constant in Sauvola's threshold formula
+	R          float64 // dynamic range of the local standard deviation
+}
+
+const (
+	defaultSauvolaWindowSize = 19
+	defaultSauvolaK          = 0.3
+	defaultSauvolaR          = 128.0
+
+	// referenceBitmapSize is the side length of the down-sampled bitmap
+	// stored as each camera's change-detection reference frame, so frames
+	// can be diffed by Hamming distance even across a camera resolution
+	// change.
+	referenceBitmapSize = 256
+)
+
+func (c SauvolaConfig) withDefaults() SauvolaConfig {
+	if c.WindowSize <= 0 {
+		c.WindowSize = defaultSauvolaWindowSize
+	}
+	if c.K <= 0 {
+		c.K = defaultSauvolaK
+	}
+	if c.R <= 0 {
+		c.R = defaultSauvolaR
+	}
+	return c
+}
+
+// sauvolaBinarize classifies every pixel of frame as foreground (true) or
+// background (false) using Sauvola's local-adaptive threshold:
+//
+//	T = mean * (1 + k*((stddev/R) - 1))
+//
+// with mean/stddev computed over a WindowSize x WindowSize window centered
+// on the pixel. A pixel below T is foreground. frame's summed-area tables
+// turn what would be an O(width*height*WindowSize^2) pass into
+// O(width*height): every window's mean/stddev is a four-corner lookup,
+// exactly like the rest of this package's enhanced analyzers.
+func sauvolaBinarize(frame *integralFrame, cfg SauvolaConfig) []bool {
+	cfg = cfg.withDefaults()
+	half := cfg.WindowSize / 2
+
+	mask := make([]bool, frame.width*frame.height)
+	for y := 0; y < frame.height; y++ {
+		absY := frame.bounds.Min.Y + y
+		for x := 0; x < frame.width; x++ {
+			absX := frame.bounds.Min.X + x
+
+			mean, _ := frame.lumMean(absX-half, absY-half, absX+half+1, absY+half+1)
+			stddev, _ := frame.lumStdDev(absX-half, absY-half, absX+half+1, absY+half+1)
+			threshold := mean * (1 + cfg.K*((stddev/cfg.R)-1))
+
+			mask[y*frame.width+x] = frame.lum[y*frame.width+x] < threshold
+		}
+	}
+	return mask
+}
+
+// downsampleMask reduces a width x height foreground mask to a size x size
+// bitmap, each cell set when at least half of the source pixels it covers
+// are foreground. This lets two masks taken at different camera resolutions
+// still be compared via Hamming distance.
+func downsampleMask(mask []bool, width, height, size int) []bool {
+	out := make([]bool, size*size)
+	if width == 0 || height == 0 {
+		return out
+	}
+
+	for cy := 0; cy < size; cy++ {
+		y0, y1 := cy*height/size, (cy+1)*height/size
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for cx := 0; cx < size; cx++ {
+			x0, x1 := cx*width/size, (cx+1)*width/size
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			total, fg := 0, 0
+			for y := y0; y < y1 && y < height; y++ {
+				row := y * width
+				for x := x0; x < x1 && x < width; x++ {
+					total++
+					if mask[row+x] {
+						fg++
+					}
+				}
+			}
+			out[cy*size+cx] = total > 0 && fg*2 >= total
+		}
+	}
+	return out
+}
+
+// packBits packs a bool slice 8-to-a-byte, for compact storage and Hamming
+// comparison.
+func packBits(bits []bool) []byte {
+	packed := make([]byte, (len(bits)+7)/8)
+	for i, b := range bits {
+		if b {
+			packed[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return packed
+}
+
+// hammingDistance returns the number of differing bits between two packed
+// bitmaps, up to the length of the shorter one.
+func hammingDistance(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+
+	dist := 0
+	for i := 0; i < n; i++ {
+		diff := a[i] ^ b[i]
+		for diff != 0 {
+			dist++
+			diff &= diff - 1
+		}
+	}
+	return dist
+}
+
+// unionFind is a standard disjoint-set with path halving and union, used by
+// largestForegroundBlob's second labeling pass.
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	return &unionFind{parent: p}
+}
+
+func (u *unionFind) find(x int) int {
+	for u.parent[x] != x {
+		u.parent[x] = u.parent[u.parent[x]]
+		x = u.parent[x]
+	}
+	return x
+}
+
+func (u *unionFind) union(a, b int) {
+	ra, rb := u.find(a), u.find(b)
+	if ra != rb {
+		u.parent[ra] = rb
+	}
+}
+
+// largestForegroundBlob finds the bounding box and pixel area of the
+// largest 4-connected foreground region in a width x height mask, using
+// standard two-pass union-find connected-component labeling: the first pass
+// assigns provisional labels and records equivalences between
+// already-visited (up/left) foreground neighbors, the second resolves every
+// label to its root and accumulates each root's bounding box and area.
+func largestForegroundBlob(mask []bool, width, height int) (*BoundingBox, int) {
+	if width == 0 || height == 0 {
+		return nil, 0
+	}
+
+	labels := make([]int, width*height)
+	uf := newUnionFind(width*height + 1)
+	nextLabel := 1
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			idx := y*width + x
+			if !mask[idx] {
+				continue
+			}
+
+			left, up := -1, -1
+			if x > 0 && mask[idx-1] {
+				left = labels[idx-1]
+			}
+			if y > 0 && mask[idx-width] {
+				up = labels[idx-width]
+			}
+
+			switch {
+			case left == -1 && up == -1:
+				labels[idx] = nextLabel
+				nextLabel++
+			case left == -1:
+				labels[idx] = up
+			case up == -1:
+				labels[idx] = left
+			default:
+				labels[idx] = left
+				uf.union(left, up)
+			}
+		}
+	}
+
+	type blobStats struct {
+		minX, minY, maxX, maxY, area int
+	}
+	stats := map[int]*blobStats{}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			idx := y*width + x
+			if !mask[idx] {
+				continue
+			}
+			root := uf.find(labels[idx])
+			s, ok := stats[root]
+			if !ok {
+				s = &blobStats{minX: x, minY: y, maxX: x, maxY: y}
+				stats[root] = s
+			}
+			if x < s.minX {
+				s.minX = x
+			}
+			if x > s.maxX {
+				s.maxX = x
+			}
+			if y < s.minY {
+				s.minY = y
+			}
+			if y > s.maxY {
+				s.maxY = y
+			}
+			s.area++
+		}
+	}
+
+	var best *blobStats
+	for _, s := range stats {
+		if best == nil || s.area > best.area {
+			best = s
+		}
+	}
+	if best == nil {
+		return nil, 0
+	}
+	return &BoundingBox{
+		X:      best.minX,
+		Y:      best.minY,
+		Width:  best.maxX - best.minX + 1,
+		Height: best.maxY - best.minY + 1,
+	}, best.area
+}
@@ -0,0 +1,96 @@
+package detection
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSauvolaBinarizeFlagsDarkBlobOnBrightBackground(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			img.Set(x, y, color.RGBA{220, 220, 220, 255})
+		}
+	}
+	for y := 15; y < 25; y++ {
+		for x := 15; x < 25; x++ {
+			img.Set(x, y, color.RGBA{20, 20, 20, 255})
+		}
+	}
+
+	frame := newIntegralFrame(img)
+	mask := sauvolaBinarize(frame, SauvolaConfig{})
+
+	if !mask[20*40+20] {
+		t.Errorf("expected the dark blob's center pixel to be classified foreground")
+	}
+	if mask[2*40+2] {
+		t.Errorf("expected the uniform bright background to be classified background")
+	}
+}
+
+func TestDownsampleMaskMajorityVote(t *testing.T) {
+	// 4x4 mask, left half foreground, right half background; downsampling
+	// to 2x2 should preserve that split.
+	mask := make([]bool, 16)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 2; x++ {
+			mask[y*4+x] = true
+		}
+	}
+
+	down := downsampleMask(mask, 4, 4, 2)
+	if !down[0] || !down[2] {
+		t.Errorf("expected the left column of the downsampled bitmap to stay foreground: %v", down)
+	}
+	if down[1] || down[3] {
+		t.Errorf("expected the right column of the downsampled bitmap to stay background: %v", down)
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	a := packBits([]bool{true, false, true, true})
+	b := packBits([]bool{true, false, false, false})
+
+	if dist := hammingDistance(a, b); dist != 2 {
+		t.Errorf("hammingDistance = %d, expected 2", dist)
+	}
+	if dist := hammingDistance(a, a); dist != 0 {
+		t.Errorf("hammingDistance of identical bitmaps = %d, expected 0", dist)
+	}
+}
+
+func TestLargestForegroundBlob(t *testing.T) {
+	width, height := 10, 10
+	mask := make([]bool, width*height)
+
+	// A 2x2 blob at (1,1) and a larger 4x3 blob at (5,5).
+	set := func(x0, y0, w, h int) {
+		for y := y0; y < y0+h; y++ {
+			for x := x0; x < x0+w; x++ {
+				mask[y*width+x] = true
+			}
+		}
+	}
+	set(1, 1, 2, 2)
+	set(5, 5, 4, 3)
+
+	bbox, area := largestForegroundBlob(mask, width, height)
+	if bbox == nil {
+		t.Fatalf("expected a bounding box, got nil")
+	}
+	if area != 12 {
+		t.Errorf("area = %d, expected 12", area)
+	}
+	if bbox.X != 5 || bbox.Y != 5 || bbox.Width != 4 || bbox.Height != 3 {
+		t.Errorf("bbox = %+v, expected {X:5 Y:5 Width:4 Height:3}", *bbox)
+	}
+}
+
+func TestLargestForegroundBlobEmptyMask(t *testing.T) {
+	mask := make([]bool, 100)
+	if bbox, area := largestForegroundBlob(mask, 10, 10); bbox != nil || area != 0 {
+		t.Errorf("expected nil bbox and 0 area for an empty mask, got %+v, %d", bbox, area)
+	}
+}
@@ -0,0 +1,35 @@
+package events
+
+import "context"
+
+// Eventer writes and queries events. Read streams matching events on a
+// channel rather than returning a slice, so a LogFile-backed implementation
+// can apply filters without loading its whole history into memory at once.
+type Eventer interface {
+	Write(Event) error
+	Read(ctx context.Context, opts ReadOptions) (<-chan Event, error)
+}
+
+// Backend selectors for New, persisted as conf.Settings.EventsBackend.
+const (
+	BackendLogFile = "logfile"
+	BackendMemory  = "memory"
+	BackendNull    = "null"
+)
+
+// DefaultLogFilePath is where the LogFile backend stores events when none is
+// specified.
+const DefaultLogFilePath = "events.log"
+
+// New builds the Eventer selected by backend, falling back to the LogFile
+// backend for an empty or unrecognized value.
+func New(backend, logFilePath string, memoryCapacity int) (Eventer, error) {
+	switch backend {
+	case BackendMemory:
+		return NewMemoryEventer(memoryCapacity), nil
+	case BackendNull:
+		return NewNullEventer(), nil
+	default:
+		return NewLogFileEventer(logFilePath)
+	}
+}
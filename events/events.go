@@ -0,0 +1,61 @@
+// Package events is a structured replacement for the ad-hoc log.Printf
+// calls timelapse and monitoring used to emit, modeled after podman's events
+// subsystem: a single Event type with Write/Read backends selectable by
+// configuration, so both the log file and the frontend can query the same
+// history instead of scraping log lines.
+package events
+
+import "time"
+
+// Type identifies what kind of event occurred.
+type Type string
+
+const (
+	Capture         Type = "CAPTURE"
+	CaptureError    Type = "CAPTURE_ERROR"
+	CameraInitError Type = "CAMERA_INIT_ERROR"
+	ConfigUpdate    Type = "CONFIG_UPDATE"
+	Startup         Type = "STARTUP"
+	Shutdown        Type = "SHUTDOWN"
+	DailyRuntime    Type = "DAILY_RUNTIME"
+	Stats           Type = "STATS"
+	CaptureMissed   Type = "CAPTURE_MISSED"
+)
+
+// Event is a single structured occurrence, e.g. a capture attempt or a
+// configuration change. Attributes carries type-specific detail (photo path,
+// duration, error message, ...) as strings so every backend can serialize it
+// the same way.
+type Event struct {
+	Type       Type              `json:"type"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// ReadOptions filters the events returned by Eventer.Read. A zero value
+// matches every event.
+type ReadOptions struct {
+	Since time.Time
+	Until time.Time
+	Type  Type
+	Attr  map[string]string
+}
+
+// Matches reports whether e satisfies every filter set in o.
+func (o ReadOptions) Matches(e Event) bool {
+	if !o.Since.IsZero() && e.Timestamp.Before(o.Since) {
+		return false
+	}
+	if !o.Until.IsZero() && e.Timestamp.After(o.Until) {
+		return false
+	}
+	if o.Type != "" && e.Type != o.Type {
+		return false
+	}
+	for k, v := range o.Attr {
+		if e.Attributes[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func drain(t *testing.T, ch <-chan Event) []Event {
+	t.Helper()
+	var got []Event
+	for event := range ch {
+		got = append(got, event)
+	}
+	return got
+}
+
+func TestMemoryEventerEvictsOldest(t *testing.T) {
+	e := NewMemoryEventer(2)
+	e.Write(Event{Type: Capture, Attributes: map[string]string{"n": "1"}})
+	e.Write(Event{Type: Capture, Attributes: map[string]string{"n": "2"}})
+	e.Write(Event{Type: Capture, Attributes: map[string]string{"n": "3"}})
+
+	ch, err := e.Read(context.Background(), ReadOptions{})
+	if err != nil {
+		t.Fatalf("Read failed: %s", err)
+	}
+	got := drain(t, ch)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events after eviction, got %d", len(got))
+	}
+	if got[0].Attributes["n"] != "2" || got[1].Attributes["n"] != "3" {
+		t.Errorf("expected oldest event to be evicted, got %+v", got)
+	}
+}
+
+func TestMemoryEventerFiltersByTypeAndAttr(t *testing.T) {
+	e := NewMemoryEventer(10)
+	e.Write(Event{Type: Capture, Attributes: map[string]string{"path": "a.jpg"}})
+	e.Write(Event{Type: CaptureError, Attributes: map[string]string{"error": "timeout"}})
+
+	ch, err := e.Read(context.Background(), ReadOptions{Type: CaptureError})
+	if err != nil {
+		t.Fatalf("Read failed: %s", err)
+	}
+	got := drain(t, ch)
+	if len(got) != 1 || got[0].Type != CaptureError {
+		t.Errorf("expected only CaptureError events, got %+v", got)
+	}
+}
+
+func TestReadOptionsMatchesSinceUntil(t *testing.T) {
+	now := time.Now()
+	opts := ReadOptions{Since: now.Add(-time.Minute), Until: now.Add(time.Minute)}
+	if !opts.Matches(Event{Timestamp: now}) {
+		t.Error("expected event within [since,until] to match")
+	}
+	if opts.Matches(Event{Timestamp: now.Add(-time.Hour)}) {
+		t.Error("expected event before since to not match")
+	}
+	if opts.Matches(Event{Timestamp: now.Add(time.Hour)}) {
+		t.Error("expected event after until to not match")
+	}
+}
+
+func TestLogFileEventerRoundTrip(t *testing.T) {
+	path := "test-events.log"
+	os.Remove(path)
+	defer os.Remove(path)
+
+	e, err := NewLogFileEventer(path)
+	if err != nil {
+		t.Fatalf("NewLogFileEventer failed: %s", err)
+	}
+	defer e.Close()
+
+	if err := e.Write(Event{Type: Startup, Attributes: map[string]string{"message": "System started"}}); err != nil {
+		t.Fatalf("Write failed: %s", err)
+	}
+
+	ch, err := e.Read(context.Background(), ReadOptions{})
+	if err != nil {
+		t.Fatalf("Read failed: %s", err)
+	}
+	got := drain(t, ch)
+	if len(got) != 1 || got[0].Type != Startup {
+		t.Errorf("expected the written event to round-trip, got %+v", got)
+	}
+}
+
+func TestNullEventer(t *testing.T) {
+	e := NewNullEventer()
+	if err := e.Write(Event{Type: Capture}); err != nil {
+		t.Errorf("expected Write to succeed, got %s", err)
+	}
+
+	ch, err := e.Read(context.Background(), ReadOptions{})
+	if err != nil {
+		t.Fatalf("Read failed: %s", err)
+	}
+	if got := drain(t, ch); len(got) != 0 {
+		t.Errorf("expected no events, got %+v", got)
+	}
+}
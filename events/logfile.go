@@ -0,0 +1,77 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// LogFileEventer appends events as JSON-lines to a file on disk, replacing
+// the old "[STATS] ..."/"[STARTUP] ..." plain-text scheme.
+type LogFileEventer struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewLogFileEventer opens (creating if necessary) path for appending.
+func NewLogFileEventer(path string) (*LogFileEventer, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &LogFileEventer{path: path, file: f}, nil
+}
+
+func (e *LogFileEventer) Write(event Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = e.file.Write(append(line, '\n'))
+	return err
+}
+
+// Read re-reads the file from disk and streams every line matching opts.
+func (e *LogFileEventer) Read(ctx context.Context, opts ReadOptions) (<-chan Event, error) {
+	e.mu.Lock()
+	data, err := os.ReadFile(e.path)
+	e.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			var event Event
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				continue
+			}
+			if !opts.Matches(event) {
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Close releases the underlying file handle.
+func (e *LogFileEventer) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.file.Close()
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryEventer keeps the most recent `capacity` events in a bounded ring,
+// for deployments that would rather not write events to the SD card.
+type MemoryEventer struct {
+	mu       sync.Mutex
+	events   []Event
+	capacity int
+}
+
+// NewMemoryEventer creates a MemoryEventer holding at most capacity events.
+func NewMemoryEventer(capacity int) *MemoryEventer {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryEventer{capacity: capacity}
+}
+
+func (e *MemoryEventer) Write(event Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.events = append(e.events, event)
+	if len(e.events) > e.capacity {
+		e.events = e.events[len(e.events)-e.capacity:]
+	}
+	return nil
+}
+
+func (e *MemoryEventer) Read(ctx context.Context, opts ReadOptions) (<-chan Event, error) {
+	e.mu.Lock()
+	snapshot := make([]Event, len(e.events))
+	copy(snapshot, e.events)
+	e.mu.Unlock()
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for _, event := range snapshot {
+			if !opts.Matches(event) {
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,21 @@
+package events
+
+import "context"
+
+// NullEventer discards every write, for tests that don't care about events.
+type NullEventer struct{}
+
+// NewNullEventer creates a NullEventer.
+func NewNullEventer() *NullEventer {
+	return &NullEventer{}
+}
+
+func (NullEventer) Write(Event) error {
+	return nil
+}
+
+func (NullEventer) Read(_ context.Context, _ ReadOptions) (<-chan Event, error) {
+	out := make(chan Event)
+	close(out)
+	return out, nil
+}
@@ -0,0 +1,519 @@
+// Package exif embeds and reads a minimal set of EXIF tags (capture
+// timestamp, GPS location, camera make/model and a free-form user comment)
+// directly in JPEG files, without shelling out or depending on a cgo-based
+// metadata library.
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"time"
+)
+
+var (
+	errNotJPEG         = errors.New("not a JPEG file (missing SOI marker)")
+	errNoExifSegment   = errors.New("no EXIF (APP1) segment found")
+	errInvalidTIFF     = errors.New("invalid TIFF header in EXIF segment")
+	dateTimeExifLayout = "2006:01:02 15:04:05"
+)
+
+// Tags holds the subset of EXIF metadata this package understands.
+type Tags struct {
+	// DateTimeOriginal is embedded and read back in UTC: the EXIF
+	// DateTimeOriginal tag carries no UTC offset (this package doesn't write
+	// an OffsetTimeOriginal tag), so a value in any other location would be
+	// reinterpreted as UTC on read, silently shifting the instant it
+	// represents. Callers that care about the original local time should
+	// keep it separately (e.g. in UserComment).
+	DateTimeOriginal time.Time
+	HasGPS           bool
+	GPSLatitude      float64 // positive = North, negative = South
+	GPSLongitude     float64 // positive = East, negative = West
+	Make             string
+	Model            string
+	UserComment      string
+	Orientation      int // TIFF Orientation tag (1-8); 0 if absent
+	// ExposureTime, FNumber and ISOSpeedRatings mirror the capture settings
+	// in timelapse.StillTuning (ExposureTime from ShutterSpeedUs, ISOSpeedRatings
+	// from ISO). FNumber is parsed but never written, since none of this
+	// project's capture backends control aperture. Zero means absent.
+	ExposureTime    time.Duration
+	FNumber         float64
+	ISOSpeedRatings int
+}
+
+const (
+	tiffTypeASCII     = 2
+	tiffTypeShort     = 3
+	tiffTypeLong      = 4
+	tiffTypeRational  = 5
+	tiffTypeUndefined = 7
+
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagExifIFDPointer   = 0x8769
+	tagGPSIFDPointer    = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagUserComment      = 0x9286
+	tagGPSLatitudeRef   = 0x0001
+	tagGPSLatitude      = 0x0002
+	tagGPSLongitudeRef  = 0x0003
+	tagGPSLongitude     = 0x0004
+	tagExposureTime     = 0x829A
+	tagFNumber          = 0x829D
+	tagISOSpeedRatings  = 0x8827
+)
+
+// ifdEntry is one row of a TIFF Image File Directory.
+type ifdEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte // raw value bytes; inlined if len<=4, otherwise placed in the data area
+}
+
+// Embed reads the JPEG at path, embeds tags as an APP1 EXIF segment, and
+// overwrites the file in place.
+func Embed(path string, tags Tags) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	out, err := EmbedBytes(data, tags)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// EmbedBytes returns jpegData with any existing APP1/EXIF segment replaced
+// by one built from tags.
+func EmbedBytes(jpegData []byte, tags Tags) ([]byte, error) {
+	if len(jpegData) < 4 || jpegData[0] != 0xFF || jpegData[1] != 0xD8 {
+		return nil, errNotJPEG
+	}
+
+	tiff, err := buildTIFF(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	app1 := make([]byte, 0, len(tiff)+8)
+	app1 = append(app1, "Exif\x00\x00"...)
+	app1 = append(app1, tiff...)
+
+	segment := make([]byte, 0, len(app1)+4)
+	segment = append(segment, 0xFF, 0xE1)
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(app1)+2))
+	segment = append(segment, app1...)
+
+	withoutOldAPP1 := stripAPP1(jpegData)
+
+	out := make([]byte, 0, len(withoutOldAPP1)+len(segment))
+	out = append(out, withoutOldAPP1[:2]...) // SOI
+	out = append(out, segment...)
+	out = append(out, withoutOldAPP1[2:]...)
+	return out, nil
+}
+
+// stripAPP1 removes an existing APP1 segment (if any) right after the SOI
+// marker so repeated Embed calls don't accumulate duplicate segments.
+func stripAPP1(jpegData []byte) []byte {
+	if len(jpegData) < 4 || jpegData[2] != 0xFF || jpegData[3] != 0xE1 {
+		return jpegData
+	}
+	segLen := int(binary.BigEndian.Uint16(jpegData[4:6]))
+	end := 4 + segLen
+	if end > len(jpegData) {
+		return jpegData
+	}
+	out := make([]byte, 0, len(jpegData)-(end-2))
+	out = append(out, jpegData[:2]...)
+	out = append(out, jpegData[end:]...)
+	return out
+}
+
+// buildTIFF serializes tags into a little-endian TIFF/EXIF blob (the part
+// that follows the "Exif\0\0" header of an APP1 segment).
+func buildTIFF(tags Tags) ([]byte, error) {
+	var ifd0 []ifdEntry
+	if tags.Make != "" {
+		ifd0 = append(ifd0, asciiEntry(tagMake, tags.Make))
+	}
+	if tags.Model != "" {
+		ifd0 = append(ifd0, asciiEntry(tagModel, tags.Model))
+	}
+
+	var exifIFD []ifdEntry
+	if !tags.DateTimeOriginal.IsZero() {
+		// Normalize to UTC before formatting: dateTimeExifLayout carries no
+		// UTC offset, so embedding any other zone's clock reading would read
+		// back, via time.Parse's UTC default, as a different instant. See
+		// Tags.DateTimeOriginal.
+		exifIFD = append(exifIFD, asciiEntry(tagDateTimeOriginal, tags.DateTimeOriginal.UTC().Format(dateTimeExifLayout)))
+	}
+	if tags.UserComment != "" {
+		value := append([]byte("ASCII\x00\x00\x00"), []byte(tags.UserComment)...)
+		exifIFD = append(exifIFD, ifdEntry{tag: tagUserComment, typ: tiffTypeUndefined, count: uint32(len(value)), value: value})
+	}
+	if tags.ExposureTime != 0 {
+		exifIFD = append(exifIFD, rationalEntry(tagExposureTime, uint32(tags.ExposureTime.Microseconds()), 1000000))
+	}
+	if tags.ISOSpeedRatings != 0 {
+		exifIFD = append(exifIFD, shortEntry(tagISOSpeedRatings, uint16(tags.ISOSpeedRatings)))
+	}
+
+	var gpsIFD []ifdEntry
+	if tags.HasGPS {
+		latRef, lonRef := "N", "E"
+		lat, lon := tags.GPSLatitude, tags.GPSLongitude
+		if lat < 0 {
+			latRef, lat = "S", -lat
+		}
+		if lon < 0 {
+			lonRef, lon = "W", -lon
+		}
+		gpsIFD = append(gpsIFD,
+			ifdEntry{tag: tagGPSLatitudeRef, typ: tiffTypeASCII, count: 2, value: []byte(latRef + "\x00")},
+			rationalDMSEntry(tagGPSLatitude, lat),
+			ifdEntry{tag: tagGPSLongitudeRef, typ: tiffTypeASCII, count: 2, value: []byte(lonRef + "\x00")},
+			rationalDMSEntry(tagGPSLongitude, lon),
+		)
+	}
+
+	const tiffHeaderLen = 8
+	ifd0Offset := uint32(tiffHeaderLen)
+	if len(exifIFD) > 0 {
+		ifd0 = append(ifd0, ifdEntry{tag: tagExifIFDPointer, typ: tiffTypeLong, count: 1}) // value patched below
+	}
+	if len(gpsIFD) > 0 {
+		ifd0 = append(ifd0, ifdEntry{tag: tagGPSIFDPointer, typ: tiffTypeLong, count: 1}) // value patched below
+	}
+
+	ifd0Bytes, ifd0Data, ifd0Len := sizeIFD(ifd0)
+	exifOffset := ifd0Offset + ifd0Len + uint32(len(ifd0Data))
+
+	exifBytes, exifData, exifLen := sizeIFD(exifIFD)
+	gpsOffset := exifOffset + exifLen + uint32(len(exifData))
+	if len(exifIFD) == 0 {
+		gpsOffset = ifd0Offset + ifd0Len + uint32(len(ifd0Data))
+	}
+
+	// Patch the pointer entries in ifd0 now that sub-IFD offsets are known.
+	for i := range ifd0 {
+		switch ifd0[i].tag {
+		case tagExifIFDPointer:
+			ifd0[i].value = le32(exifOffset)
+		case tagGPSIFDPointer:
+			ifd0[i].value = le32(gpsOffset)
+		}
+	}
+	// Re-serialize ifd0 now that pointer values are filled in (sizes unchanged).
+	ifd0Bytes, ifd0Data, _ = sizeIFD(ifd0)
+
+	var buf bytes.Buffer
+	buf.WriteString("II")
+	binary.Write(&buf, binary.LittleEndian, uint16(42))
+	binary.Write(&buf, binary.LittleEndian, ifd0Offset)
+	buf.Write(serializeIFD(ifd0Offset, ifd0Bytes, ifd0Data, 0))
+
+	if len(exifIFD) > 0 {
+		buf.Write(serializeIFD(exifOffset, exifBytes, exifData, 0))
+	}
+	if len(gpsIFD) > 0 {
+		gpsBytes, gpsData, _ := sizeIFD(gpsIFD)
+		buf.Write(serializeIFD(gpsOffset, gpsBytes, gpsData, 0))
+	}
+
+	return buf.Bytes(), nil
+}
+
+func asciiEntry(tag uint16, s string) ifdEntry {
+	value := append([]byte(s), 0)
+	return ifdEntry{tag: tag, typ: tiffTypeASCII, count: uint32(len(value)), value: value}
+}
+
+// rationalDMSEntry encodes a decimal-degrees coordinate as three EXIF
+// RATIONALs (degrees, minutes, seconds), each a numerator/denominator pair.
+func rationalDMSEntry(tag uint16, decimalDegrees float64) ifdEntry {
+	degrees := int(decimalDegrees)
+	minutesFloat := (decimalDegrees - float64(degrees)) * 60
+	minutes := int(minutesFloat)
+	seconds := (minutesFloat - float64(minutes)) * 60
+
+	var value []byte
+	value = binary.LittleEndian.AppendUint32(value, uint32(degrees))
+	value = binary.LittleEndian.AppendUint32(value, 1)
+	value = binary.LittleEndian.AppendUint32(value, uint32(minutes))
+	value = binary.LittleEndian.AppendUint32(value, 1)
+	value = binary.LittleEndian.AppendUint32(value, uint32(seconds*1000))
+	value = binary.LittleEndian.AppendUint32(value, 1000)
+
+	return ifdEntry{tag: tag, typ: tiffTypeRational, count: 3, value: value}
+}
+
+// rationalEntry encodes a single EXIF RATIONAL (a numerator/denominator
+// pair), e.g. an exposure time of num/den seconds.
+func rationalEntry(tag uint16, num, den uint32) ifdEntry {
+	var value []byte
+	value = binary.LittleEndian.AppendUint32(value, num)
+	value = binary.LittleEndian.AppendUint32(value, den)
+	return ifdEntry{tag: tag, typ: tiffTypeRational, count: 1, value: value}
+}
+
+// shortEntry encodes a single EXIF SHORT value.
+func shortEntry(tag uint16, v uint16) ifdEntry {
+	return ifdEntry{tag: tag, typ: tiffTypeShort, count: 1, value: binary.LittleEndian.AppendUint16(nil, v)}
+}
+
+func le32(v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, v)
+}
+
+// sizeIFD lays out entries (sorted by tag, as required by the TIFF spec)
+// returning the serialized entry rows, the overflow data area, and the
+// total byte length of the IFD itself (count + entries + next-IFD offset).
+func sizeIFD(entries []ifdEntry) (rows []ifdEntry, data []byte, ifdLen uint32) {
+	sorted := append([]ifdEntry(nil), entries...)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j-1].tag > sorted[j].tag; j-- {
+			sorted[j-1], sorted[j] = sorted[j], sorted[j-1]
+		}
+	}
+	ifdLen = uint32(2 + 12*len(sorted) + 4)
+	var dataBuf bytes.Buffer
+	for _, e := range sorted {
+		if len(e.value) > 4 {
+			dataBuf.Write(e.value)
+			if len(e.value)%2 == 1 {
+				dataBuf.WriteByte(0)
+			}
+		}
+	}
+	return sorted, dataBuf.Bytes(), ifdLen
+}
+
+// serializeIFD writes out the count, entries, and next-IFD offset for an
+// IFD located at baseOffset, inlining small values or pointing at the data
+// area (which immediately follows the entry rows) for larger ones.
+func serializeIFD(baseOffset uint32, rows []ifdEntry, data []byte, nextIFDOffset uint32) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint16(len(rows)))
+
+	dataOffset := baseOffset + uint32(2+12*len(rows)+4)
+	dataPos := uint32(0)
+	for _, e := range rows {
+		binary.Write(&buf, binary.LittleEndian, e.tag)
+		binary.Write(&buf, binary.LittleEndian, e.typ)
+		binary.Write(&buf, binary.LittleEndian, e.count)
+
+		var valueField [4]byte
+		if len(e.value) <= 4 {
+			copy(valueField[:], e.value)
+		} else {
+			binary.LittleEndian.PutUint32(valueField[:], dataOffset+dataPos)
+			size := len(e.value)
+			if size%2 == 1 {
+				size++
+			}
+			dataPos += uint32(size)
+		}
+		buf.Write(valueField[:])
+	}
+	binary.Write(&buf, binary.LittleEndian, nextIFDOffset)
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+// Read extracts the EXIF tags embedded in the JPEG at path.
+func Read(path string) (Tags, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Tags{}, err
+	}
+	return ReadBytes(data)
+}
+
+// ReadBytes parses the APP1/EXIF segment (if any) out of jpegData.
+func ReadBytes(jpegData []byte) (Tags, error) {
+	if len(jpegData) < 4 || jpegData[0] != 0xFF || jpegData[1] != 0xD8 {
+		return Tags{}, errNotJPEG
+	}
+	if len(jpegData) < 10 || jpegData[2] != 0xFF || jpegData[3] != 0xE1 {
+		return Tags{}, errNoExifSegment
+	}
+	segLen := int(binary.BigEndian.Uint16(jpegData[4:6]))
+	if 4+segLen > len(jpegData) {
+		return Tags{}, errNoExifSegment
+	}
+	app1 := jpegData[6 : 4+segLen]
+	if !bytes.HasPrefix(app1, []byte("Exif\x00\x00")) {
+		return Tags{}, errNoExifSegment
+	}
+	tiff := app1[6:]
+	return parseTIFF(tiff)
+}
+
+func parseTIFF(tiff []byte) (Tags, error) {
+	if len(tiff) < 8 || tiff[0] != 'I' || tiff[1] != 'I' {
+		return Tags{}, errInvalidTIFF
+	}
+	ifd0Offset := binary.LittleEndian.Uint32(tiff[4:8])
+
+	var tags Tags
+	ifd0, err := parseIFD(tiff, ifd0Offset)
+	if err != nil {
+		return Tags{}, err
+	}
+
+	for _, e := range ifd0 {
+		switch e.tag {
+		case tagMake:
+			tags.Make = asciiValue(e)
+		case tagModel:
+			tags.Model = asciiValue(e)
+		case tagOrientation:
+			if len(e.value) >= 2 {
+				tags.Orientation = int(binary.LittleEndian.Uint16(e.value))
+			}
+		case tagExifIFDPointer:
+			exifIFD, err := parseIFD(tiff, binary.LittleEndian.Uint32(e.value))
+			if err == nil {
+				for _, se := range exifIFD {
+					switch se.tag {
+					case tagDateTimeOriginal:
+						if t, err := time.Parse(dateTimeExifLayout, asciiValue(se)); err == nil {
+							tags.DateTimeOriginal = t
+						}
+					case tagUserComment:
+						if len(se.value) > 8 {
+							tags.UserComment = string(bytes.TrimRight(se.value[8:], "\x00"))
+						}
+					case tagExposureTime:
+						if len(se.value) >= 8 {
+							tags.ExposureTime = time.Duration(rationalAt(se.value, 0) * float64(time.Second))
+						}
+					case tagFNumber:
+						if len(se.value) >= 8 {
+							tags.FNumber = rationalAt(se.value, 0)
+						}
+					case tagISOSpeedRatings:
+						if len(se.value) >= 2 {
+							tags.ISOSpeedRatings = int(binary.LittleEndian.Uint16(se.value))
+						}
+					}
+				}
+			}
+		case tagGPSIFDPointer:
+			gpsIFD, err := parseIFD(tiff, binary.LittleEndian.Uint32(e.value))
+			if err == nil {
+				lat, latOK := gpsCoordinate(gpsIFD, tagGPSLatitude, tagGPSLatitudeRef, "S")
+				lon, lonOK := gpsCoordinate(gpsIFD, tagGPSLongitude, tagGPSLongitudeRef, "W")
+				if latOK && lonOK {
+					tags.HasGPS = true
+					tags.GPSLatitude = lat
+					tags.GPSLongitude = lon
+				}
+			}
+		}
+	}
+	return tags, nil
+}
+
+// parsedEntry is an in-memory IFD entry with its value resolved (inline or
+// read from the TIFF's data area).
+type parsedEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+func parseIFD(tiff []byte, offset uint32) ([]parsedEntry, error) {
+	if int(offset)+2 > len(tiff) {
+		return nil, errInvalidTIFF
+	}
+	count := binary.LittleEndian.Uint16(tiff[offset : offset+2])
+	entries := make([]parsedEntry, 0, count)
+	pos := offset + 2
+	for i := uint16(0); i < count; i++ {
+		if int(pos)+12 > len(tiff) {
+			return nil, errInvalidTIFF
+		}
+		tag := binary.LittleEndian.Uint16(tiff[pos : pos+2])
+		typ := binary.LittleEndian.Uint16(tiff[pos+2 : pos+4])
+		cnt := binary.LittleEndian.Uint32(tiff[pos+4 : pos+8])
+		rawValue := tiff[pos+8 : pos+12]
+
+		size := int(cnt) * typeSize(typ)
+		var value []byte
+		if size <= 4 {
+			value = rawValue[:max(size, 0)]
+		} else {
+			dataOffset := binary.LittleEndian.Uint32(rawValue)
+			if int(dataOffset)+size > len(tiff) {
+				return nil, errInvalidTIFF
+			}
+			value = tiff[dataOffset : int(dataOffset)+size]
+		}
+		entries = append(entries, parsedEntry{tag: tag, typ: typ, count: cnt, value: value})
+		pos += 12
+	}
+	return entries, nil
+}
+
+func typeSize(typ uint16) int {
+	switch typ {
+	case tiffTypeASCII, tiffTypeUndefined:
+		return 1
+	case tiffTypeShort:
+		return 2
+	case tiffTypeLong:
+		return 4
+	case tiffTypeRational:
+		return 8
+	default:
+		return 1
+	}
+}
+
+func asciiValue(e parsedEntry) string {
+	return string(bytes.TrimRight(e.value, "\x00"))
+}
+
+func gpsCoordinate(ifd []parsedEntry, valueTag, refTag uint16, negativeRef string) (float64, bool) {
+	var ref string
+	var rational []byte
+	for _, e := range ifd {
+		if e.tag == refTag {
+			ref = asciiValue(e)
+		}
+		if e.tag == valueTag {
+			rational = e.value
+		}
+	}
+	if rational == nil || len(rational) < 24 {
+		return 0, false
+	}
+	degrees := rationalAt(rational, 0)
+	minutes := rationalAt(rational, 1)
+	seconds := rationalAt(rational, 2)
+	value := degrees + minutes/60 + seconds/3600
+	if ref == negativeRef {
+		value = -value
+	}
+	return value, true
+}
+
+func rationalAt(value []byte, index int) float64 {
+	offset := index * 8
+	num := binary.LittleEndian.Uint32(value[offset : offset+4])
+	den := binary.LittleEndian.Uint32(value[offset+4 : offset+8])
+	if den == 0 {
+		return 0
+	}
+	return float64(num) / float64(den)
+}
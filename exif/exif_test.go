@@ -0,0 +1,159 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/facebookgo/ensure"
+)
+
+// minimalJPEG is a bare SOI+EOI JPEG with no payload, enough for EmbedBytes
+// to attach an APP1 segment to.
+var minimalJPEG = []byte{0xFF, 0xD8, 0xFF, 0xD9}
+
+func TestEmbedAndReadRoundTrip(t *testing.T) {
+	tags := Tags{
+		DateTimeOriginal: time.Date(2026, 7, 26, 14, 30, 0, 0, time.UTC),
+		Make:             "Raspberry Pi Foundation",
+		Model:            "Camera Module",
+		UserComment:      "2 objects detected",
+	}
+
+	out, err := EmbedBytes(minimalJPEG, tags)
+	ensure.Nil(t, err)
+
+	got, err := ReadBytes(out)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, tags.DateTimeOriginal, got.DateTimeOriginal)
+	ensure.DeepEqual(t, tags.Make, got.Make)
+	ensure.DeepEqual(t, tags.Model, got.Model)
+	ensure.DeepEqual(t, tags.UserComment, got.UserComment)
+	ensure.False(t, got.HasGPS)
+}
+
+func TestEmbedAndReadRoundTripNonUTC(t *testing.T) {
+	pacific := time.FixedZone("PDT", -7*60*60)
+	tags := Tags{DateTimeOriginal: time.Date(2026, 7, 26, 14, 30, 0, 0, pacific)}
+
+	out, err := EmbedBytes(minimalJPEG, tags)
+	ensure.Nil(t, err)
+
+	got, err := ReadBytes(out)
+	ensure.Nil(t, err)
+	// DateTimeOriginal round-trips through UTC (see Tags.DateTimeOriginal), so
+	// the value read back is the same instant, not the same clock reading.
+	ensure.True(t, tags.DateTimeOriginal.Equal(got.DateTimeOriginal))
+}
+
+func TestEmbedAndReadExposureAndISO(t *testing.T) {
+	tags := Tags{
+		ExposureTime:    250 * time.Millisecond,
+		ISOSpeedRatings: 800,
+	}
+
+	out, err := EmbedBytes(minimalJPEG, tags)
+	ensure.Nil(t, err)
+
+	got, err := ReadBytes(out)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, tags.ISOSpeedRatings, got.ISOSpeedRatings)
+	ensure.True(t, withinTolerance(tags.ExposureTime.Seconds(), got.ExposureTime.Seconds(), 0.001))
+}
+
+func TestEmbedAndReadGPSNorthEast(t *testing.T) {
+	tags := Tags{HasGPS: true, GPSLatitude: 37.7749, GPSLongitude: 122.4194}
+
+	out, err := EmbedBytes(minimalJPEG, tags)
+	ensure.Nil(t, err)
+
+	got, err := ReadBytes(out)
+	ensure.Nil(t, err)
+	ensure.True(t, got.HasGPS)
+	ensure.True(t, withinTolerance(tags.GPSLatitude, got.GPSLatitude, 0.001))
+	ensure.True(t, withinTolerance(tags.GPSLongitude, got.GPSLongitude, 0.001))
+}
+
+func TestEmbedAndReadGPSSouthWest(t *testing.T) {
+	tags := Tags{HasGPS: true, GPSLatitude: -33.8688, GPSLongitude: -70.6693}
+
+	out, err := EmbedBytes(minimalJPEG, tags)
+	ensure.Nil(t, err)
+
+	got, err := ReadBytes(out)
+	ensure.Nil(t, err)
+	ensure.True(t, got.HasGPS)
+	ensure.True(t, withinTolerance(tags.GPSLatitude, got.GPSLatitude, 0.001))
+	ensure.True(t, withinTolerance(tags.GPSLongitude, got.GPSLongitude, 0.001))
+}
+
+func TestEmbedReplacesExistingAPP1(t *testing.T) {
+	first, err := EmbedBytes(minimalJPEG, Tags{Make: "First"})
+	ensure.Nil(t, err)
+
+	second, err := EmbedBytes(first, Tags{Make: "Second"})
+	ensure.Nil(t, err)
+
+	got, err := ReadBytes(second)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, "Second", got.Make)
+	ensure.False(t, bytes.Contains(second, []byte("First")))
+}
+
+func TestReadBytesRejectsNonJPEG(t *testing.T) {
+	_, err := ReadBytes([]byte("not a jpeg"))
+	ensure.DeepEqual(t, errNotJPEG, err)
+}
+
+func TestReadBytesNoExifSegment(t *testing.T) {
+	_, err := ReadBytes(minimalJPEG)
+	ensure.DeepEqual(t, errNoExifSegment, err)
+}
+
+func TestParseTIFFOrientation(t *testing.T) {
+	tags, err := parseTIFF(buildMinimalOrientationTIFF(6))
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, tags.Orientation, 6)
+}
+
+func TestParseTIFFOrientationAbsent(t *testing.T) {
+	tags, err := parseTIFF(buildMinimalOrientationTIFF(0))
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, tags.Orientation, 0)
+}
+
+// buildMinimalOrientationTIFF constructs a minimal little-endian TIFF buffer
+// with a single IFD0 entry for the Orientation tag, for exercising
+// parseTIFF without a full JPEG/EXIF wrapper. An orientation of 0 omits the
+// entry entirely, to exercise the no-tag case.
+func buildMinimalOrientationTIFF(orientation uint16) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("II")
+	buf.Write([]byte{0x2A, 0x00})
+	buf.Write(le32(8))
+
+	if orientation == 0 {
+		buf.Write([]byte{0x00, 0x00}) // 0 entries
+		buf.Write(le32(0))
+		return buf.Bytes()
+	}
+
+	buf.Write([]byte{0x01, 0x00}) // 1 entry
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:2], tagOrientation)
+	binary.LittleEndian.PutUint16(entry[2:4], tiffTypeShort)
+	binary.LittleEndian.PutUint32(entry[4:8], 1)
+	binary.LittleEndian.PutUint16(entry[8:10], orientation)
+	buf.Write(entry)
+	buf.Write(le32(0)) // next IFD offset: none
+	return buf.Bytes()
+}
+
+func withinTolerance(a, b, tolerance float64) bool {
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= tolerance
+}
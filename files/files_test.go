@@ -2,6 +2,8 @@ package files
 
 import (
 	"archive/tar"
+	"compress/gzip"
+	"context"
 	"io"
 	"io/ioutil"
 	"os"
@@ -9,17 +11,42 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/facebookgo/ensure"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/exif"
 )
 
 func TestListFiles(t *testing.T) {
 	w, _ := os.Getwd()
-	list, e := ListFiles(w, true)
+	list, e := ListFiles(w, true, false)
 	ensure.Nil(t, e)
 	ensure.DeepEqual(t, 2, len(list))
 }
 
+func TestListFilesWithExifSummary(t *testing.T) {
+	dir := t.TempDir()
+	captureTime := time.Date(2026, 7, 26, 10, 0, 0, 0, time.UTC)
+	jpegData, err := exif.EmbedBytes([]byte{0xFF, 0xD8, 0xFF, 0xD9}, exif.Tags{
+		DateTimeOriginal: captureTime,
+		ISOSpeedRatings:  400,
+	})
+	ensure.Nil(t, err)
+	ensure.Nil(t, os.WriteFile(dir+"/photo.jpg", jpegData, 0644))
+
+	list, err := ListFiles(dir, true, true)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, 1, len(list))
+	ensure.NotNil(t, list[0].Exif)
+	ensure.DeepEqual(t, captureTime, list[0].Exif.CaptureTime)
+	ensure.DeepEqual(t, 400, list[0].Exif.ISO)
+
+	// includeExif=false leaves Exif nil even for the same file.
+	listWithoutExif, err := ListFiles(dir, true, false)
+	ensure.Nil(t, err)
+	ensure.True(t, listWithoutExif[0].Exif == nil)
+}
+
 func TestGetFile(t *testing.T) {
 	content, e := GetFile("files.go")
 	ensure.Nil(t, e)
@@ -50,8 +77,9 @@ func TestTarTwoFilesWithPipe(t *testing.T) {
 	f := []string{"files.go", "files_test.go"}
 	pr, pw := io.Pipe()
 
+	var lastProgress Progress
 	go func() {
-		err := TarWithPipes(f, pw)
+		err := TarWithPipes(context.Background(), f, pw, func(p Progress) { lastProgress = p })
 		ensure.Nil(t, err)
 		defer pw.Close()
 	}()
@@ -75,6 +103,64 @@ func TestTarTwoFilesWithPipe(t *testing.T) {
 		count++
 	}
 	ensure.DeepEqual(t, count, 2)
+	ensure.DeepEqual(t, 2, lastProgress.FilesWritten)
+	ensure.DeepEqual(t, 2, lastProgress.TotalFiles)
+}
+
+func TestTarGzTwoFilesWithPipe(t *testing.T) {
+	f := []string{"files.go", "files_test.go"}
+	pr, pw := io.Pipe()
+
+	var lastProgress Progress
+	go func() {
+		err := TarGzWithPipes(context.Background(), f, pw, func(p Progress) { lastProgress = p }, gzip.DefaultCompression, 16, 2)
+		ensure.Nil(t, err)
+		defer pw.Close()
+	}()
+
+	gr, err := gzip.NewReader(pr)
+	ensure.Nil(t, err)
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+
+	count := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break // end of tar archive
+		}
+		ensure.Nil(t, err)
+		ensure.DeepEqual(t, f[count], hdr.Name)
+
+		fileContent, _ := ioutil.ReadFile(f[count])
+		ensure.DeepEqual(t, int64(len(fileContent)), hdr.Size)
+
+		gotContent, err := ioutil.ReadAll(tr)
+		ensure.Nil(t, err)
+		ensure.DeepEqual(t, fileContent, gotContent)
+
+		count++
+	}
+	ensure.DeepEqual(t, count, 2)
+	ensure.DeepEqual(t, 2, lastProgress.FilesWritten)
+	ensure.DeepEqual(t, 2, lastProgress.TotalFiles)
+}
+
+func TestTarWithPipesCancelledContext(t *testing.T) {
+	f := []string{"files.go", "files_test.go"}
+	pr, pw := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	go func() {
+		TarWithPipes(ctx, f, pw, nil)
+		pw.Close()
+	}()
+
+	_, err := ioutil.ReadAll(pr)
+	ensure.Nil(t, err)
 }
 
 // Benchmarks for performance-sensitive operations
@@ -83,7 +169,7 @@ func BenchmarkListFiles(b *testing.B) {
 	w, _ := os.Getwd()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = ListFiles(w, true)
+		_, _ = ListFiles(w, true, false)
 	}
 }
 
@@ -97,7 +183,7 @@ func BenchmarkByAgeSort(b *testing.B) {
 			Bytes:        1024 * int64(i),
 		}
 	}
-	
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		// Copy to avoid sorting already sorted data
@@ -117,7 +203,7 @@ func BenchmarkByAgeSortFull(b *testing.B) {
 			Bytes:        1024 * int64(i),
 		}
 	}
-	
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		// Copy to avoid sorting already sorted data
@@ -0,0 +1,88 @@
+//go:build homekit
+// +build homekit
+
+// Package hkcam publishes the capture device as a HomeKit IP camera
+// accessory using github.com/brutella/hap, so the same Pi that's shooting a
+// timelapse also shows up as a camera in the Home app.
+//
+// brutella/hap v0.0.35 only implements the HomeKit *signaling* side of an IP
+// camera: accessory.NewCamera registers the CameraControl and
+// CameraRTPStreamManagement services a HomeKit controller needs to discover
+// and pair with a camera, but the library has no stream- or
+// snapshot-provider hook to plug an ffmpeg process into (unlike some other
+// HomeKit implementations). So this package pairs and advertises the
+// accessory - it shows up as a camera in the Home app - but doesn't yet
+// serve live video or snapshots; that would mean implementing the RTP
+// SETUP/SET_CONFIG characteristic writes and the SRTP media session by hand
+// against hap's lower-level primitives, which is future work.
+//
+// This subsystem is opt-in at two levels: it's compiled in only with the
+// "homekit" build tag (see hkcam_fallback.go for the no-op binary default),
+// and even then only runs when conf.Settings.HomeKitEnabled is set.
+package hkcam
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/brutella/hap"
+	"github.com/brutella/hap/accessory"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+)
+
+// pairingDirName is where hap persists its pairing/accessory database,
+// under conf.StorageFolder, so pairing survives restarts without mixing
+// pairing state into the timelapse photo directory itself.
+const pairingDirName = "homekit"
+
+// Options configures a Server.
+type Options struct {
+	// StorageFolder is conf.StorageFolder: pairing state is persisted under
+	// StorageFolder/homekit/.
+	StorageFolder string
+	BridgeName    string // conf.Settings.HomeKitBridgeName; defaults to conf.DefaultHomeKitBridgeName if empty
+	PIN           string // conf.Settings.HomeKitPIN; defaults to conf.DefaultHomeKitPIN if empty
+}
+
+// Server is a running HomeKit IP camera accessory.
+type Server struct {
+	opts   Options
+	hap    *hap.Server
+	camera *accessory.Camera
+}
+
+// New constructs a Server but does not start serving; call Run. Pairing
+// state is read from (and, once paired, written to)
+// opts.StorageFolder/homekit/.
+func New(opts Options) (*Server, error) {
+	if opts.BridgeName == "" {
+		opts.BridgeName = conf.DefaultHomeKitBridgeName
+	}
+	if opts.PIN == "" {
+		opts.PIN = conf.DefaultHomeKitPIN
+	}
+
+	cam := accessory.NewCamera(accessory.Info{
+		Name:         opts.BridgeName,
+		Manufacturer: conf.DefaultCameraMake,
+		Model:        conf.DefaultCameraModel,
+	})
+
+	store := hap.NewFsStore(filepath.Join(opts.StorageFolder, pairingDirName))
+	hapServer, err := hap.NewServer(store, cam.A)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HomeKit server: %w", err)
+	}
+	hapServer.Pin = opts.PIN
+
+	return &Server{opts: opts, hap: hapServer, camera: cam}, nil
+}
+
+// Run serves the accessory until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	log.Printf("Starting HomeKit camera accessory %q (pairing state under %s)\n", s.opts.BridgeName, filepath.Join(s.opts.StorageFolder, pairingDirName))
+	return s.hap.ListenAndServe(ctx)
+}
@@ -0,0 +1,37 @@
+//go:build !homekit
+
+// Package hkcam publishes the capture device as a HomeKit IP camera
+// accessory. See hkcam.go for the real implementation, built with the
+// "homekit" tag against github.com/brutella/hap.
+package hkcam
+
+import (
+	"context"
+	"errors"
+)
+
+// errDisabled is returned by Run on the no-op Server this build produces,
+// in the unlikely case a caller invokes it without checking New's error.
+var errDisabled = errors.New("hkcam: built without the \"homekit\" tag")
+
+// Options configures a Server. Present here only so callers can build one
+// without a build-tag-gated import.
+type Options struct {
+	StorageFolder string
+	BridgeName    string
+	PIN           string
+}
+
+// Server is a no-op without the homekit build tag.
+type Server struct{}
+
+// New always fails without the homekit build tag, so callers fall back to
+// running without a HomeKit accessory (see main.go).
+func New(opts Options) (*Server, error) {
+	return nil, errDisabled
+}
+
+// Run never returns successfully; see New.
+func (s *Server) Run(ctx context.Context) error {
+	return errDisabled
+}
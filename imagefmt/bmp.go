@@ -0,0 +1,160 @@
+// Package imagefmt registers decoders for the BMP and baseline
+// (uncompressed) TIFF formats with the standard image package, so
+// detection.AnalyzePhotoWithConfig can accept dcraw/libcamera-still TIFF
+// captures and BMP frames in addition to JPEG/PNG.
+//
+// There's no vendored copy of golang.org/x/image available in this
+// environment (no go.sum, no network access to fetch one), so both formats
+// are decoded by a small hand-rolled parser instead of the x/image/bmp and
+// x/image/tiff packages, following the same in-tree-over-new-dependency
+// approach as the exif and preprocess packages.
+package imagefmt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+func init() {
+	image.RegisterFormat("bmp", "BM", decodeBMP, decodeBMPConfig)
+}
+
+// decodeBMP decodes an uncompressed (BI_RGB) 24-bit or 32-bit BMP, or an
+// 8-bit palette BMP. Compressed BMPs (RLE4/RLE8/bitfields) are not
+// supported.
+func decodeBMP(r io.Reader) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read BMP: %v", err)
+	}
+	hdr, err := parseBMPHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, hdr.width, hdr.height))
+	rowSize := ((hdr.width*hdr.bitCount + 31) / 32) * 4 // rows are padded to a 4-byte boundary
+
+	for y := 0; y < hdr.height; y++ {
+		// BMP rows are stored bottom-up unless biHeight is negative.
+		srcY := y
+		if !hdr.topDown {
+			srcY = hdr.height - 1 - y
+		}
+		rowStart := hdr.pixelOffset + srcY*rowSize
+		if rowStart+rowSize > len(data) {
+			return nil, fmt.Errorf("BMP pixel data truncated at row %d", y)
+		}
+		row := data[rowStart : rowStart+rowSize]
+
+		for x := 0; x < hdr.width; x++ {
+			r, g, b, err := hdr.pixelAt(row, x)
+			if err != nil {
+				return nil, err
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: r, G: g, B: b, A: 255})
+		}
+	}
+	return img, nil
+}
+
+func decodeBMPConfig(r io.Reader) (image.Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("failed to read BMP: %v", err)
+	}
+	hdr, err := parseBMPHeader(data)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.NRGBAModel, Width: hdr.width, Height: hdr.height}, nil
+}
+
+type bmpHeader struct {
+	width       int
+	height      int
+	topDown     bool
+	bitCount    int
+	pixelOffset int
+	palette     []color.NRGBA // only populated for 8-bit BMPs
+}
+
+func parseBMPHeader(data []byte) (*bmpHeader, error) {
+	if len(data) < 54 || data[0] != 'B' || data[1] != 'M' {
+		return nil, fmt.Errorf("not a BMP file")
+	}
+
+	pixelOffset := int(binary.LittleEndian.Uint32(data[10:14]))
+	infoHeaderSize := int(binary.LittleEndian.Uint32(data[14:18]))
+	if infoHeaderSize < 40 || len(data) < 14+infoHeaderSize {
+		return nil, fmt.Errorf("unsupported BMP info header size: %d", infoHeaderSize)
+	}
+
+	width := int(int32(binary.LittleEndian.Uint32(data[18:22])))
+	rawHeight := int32(binary.LittleEndian.Uint32(data[22:26]))
+	bitCount := int(binary.LittleEndian.Uint16(data[28:30]))
+	compression := binary.LittleEndian.Uint32(data[30:34])
+	if compression != 0 {
+		return nil, fmt.Errorf("unsupported BMP compression: %d", compression)
+	}
+	if bitCount != 8 && bitCount != 24 && bitCount != 32 {
+		return nil, fmt.Errorf("unsupported BMP bit depth: %d", bitCount)
+	}
+
+	hdr := &bmpHeader{
+		width:       width,
+		height:      int(rawHeight),
+		topDown:     rawHeight < 0,
+		bitCount:    bitCount,
+		pixelOffset: pixelOffset,
+	}
+	if hdr.topDown {
+		hdr.height = -hdr.height
+	}
+
+	if bitCount == 8 {
+		paletteStart := 14 + infoHeaderSize
+		paletteEntries := (pixelOffset - paletteStart) / 4
+		hdr.palette = make([]color.NRGBA, paletteEntries)
+		for i := 0; i < paletteEntries; i++ {
+			off := paletteStart + i*4
+			if off+4 > len(data) {
+				break
+			}
+			hdr.palette[i] = color.NRGBA{R: data[off+2], G: data[off+1], B: data[off], A: 255}
+		}
+	}
+
+	return hdr, nil
+}
+
+// pixelAt returns the (r, g, b) color of pixel x within a single decoded
+// BMP row.
+func (h *bmpHeader) pixelAt(row []byte, x int) (r, g, b uint8, err error) {
+	switch h.bitCount {
+	case 8:
+		idx := int(row[x])
+		if idx >= len(h.palette) {
+			return 0, 0, 0, fmt.Errorf("BMP palette index %d out of range", idx)
+		}
+		c := h.palette[idx]
+		return c.R, c.G, c.B, nil
+	case 24:
+		off := x * 3
+		if off+3 > len(row) {
+			return 0, 0, 0, fmt.Errorf("BMP row truncated at pixel %d", x)
+		}
+		return row[off+2], row[off+1], row[off], nil
+	case 32:
+		off := x * 4
+		if off+4 > len(row) {
+			return 0, 0, 0, fmt.Errorf("BMP row truncated at pixel %d", x)
+		}
+		return row[off+2], row[off+1], row[off], nil
+	default:
+		return 0, 0, 0, fmt.Errorf("unsupported BMP bit depth: %d", h.bitCount)
+	}
+}
@@ -0,0 +1,81 @@
+package imagefmt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// buildBMP24 encodes a tiny top-down, uncompressed 24-bit BMP by hand, so
+// decodeBMP can be tested without a golden file on disk.
+func buildBMP24(t *testing.T, width, height int, pixels [][]color.RGBA) []byte {
+	t.Helper()
+
+	rowSize := ((width*24 + 31) / 32) * 4
+	pixelDataSize := rowSize * height
+	pixelOffset := 14 + 40
+	fileSize := pixelOffset + pixelDataSize
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('B')
+	buf.WriteByte('M')
+	binary.Write(buf, binary.LittleEndian, uint32(fileSize))
+	binary.Write(buf, binary.LittleEndian, uint32(0)) // reserved
+	binary.Write(buf, binary.LittleEndian, uint32(pixelOffset))
+
+	binary.Write(buf, binary.LittleEndian, uint32(40))             // biSize
+	binary.Write(buf, binary.LittleEndian, int32(width))           // biWidth
+	binary.Write(buf, binary.LittleEndian, int32(-height))         // biHeight (negative = top-down)
+	binary.Write(buf, binary.LittleEndian, uint16(1))               // biPlanes
+	binary.Write(buf, binary.LittleEndian, uint16(24))              // biBitCount
+	binary.Write(buf, binary.LittleEndian, uint32(0))               // biCompression (BI_RGB)
+	binary.Write(buf, binary.LittleEndian, uint32(pixelDataSize))   // biSizeImage
+	binary.Write(buf, binary.LittleEndian, int32(2835))             // biXPelsPerMeter
+	binary.Write(buf, binary.LittleEndian, int32(2835))             // biYPelsPerMeter
+	binary.Write(buf, binary.LittleEndian, uint32(0))               // biClrUsed
+	binary.Write(buf, binary.LittleEndian, uint32(0))               // biClrImportant
+
+	for y := 0; y < height; y++ {
+		row := make([]byte, rowSize)
+		for x := 0; x < width; x++ {
+			c := pixels[y][x]
+			row[x*3] = c.B
+			row[x*3+1] = c.G
+			row[x*3+2] = c.R
+		}
+		buf.Write(row)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeBMP24(t *testing.T) {
+	red := color.RGBA{R: 200, G: 10, B: 10, A: 255}
+	blue := color.RGBA{R: 10, G: 10, B: 200, A: 255}
+	data := buildBMP24(t, 2, 2, [][]color.RGBA{{red, blue}, {blue, red}})
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("image.Decode returned an error: %v", err)
+	}
+	if format != "bmp" {
+		t.Errorf("expected format bmp, got %s", format)
+	}
+
+	got := img.At(0, 0)
+	r, g, b, _ := got.RGBA()
+	if uint8(r>>8) != red.R || uint8(g>>8) != red.G || uint8(b>>8) != red.B {
+		t.Errorf("pixel (0,0) = %v, want %v", got, red)
+	}
+}
+
+func TestDecodeBMPRejectsCompressed(t *testing.T) {
+	data := buildBMP24(t, 1, 1, [][]color.RGBA{{{R: 1, G: 2, B: 3, A: 255}}})
+	// biCompression is at offset 30
+	binary.LittleEndian.PutUint32(data[30:34], 1) // BI_RLE8
+
+	if _, err := decodeBMP(bytes.NewReader(data)); err == nil {
+		t.Error("expected an error for compressed BMP, got nil")
+	}
+}
@@ -0,0 +1,243 @@
+package imagefmt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+func init() {
+	image.RegisterFormat("tiff", "II*\x00", decodeTIFF, decodeTIFFConfig)
+	image.RegisterFormat("tiff", "MM\x00*", decodeTIFF, decodeTIFFConfig)
+}
+
+const (
+	tiffTagImageWidth      = 256
+	tiffTagImageLength     = 257
+	tiffTagBitsPerSample   = 258
+	tiffTagCompression     = 259
+	tiffTagPhotometric     = 262
+	tiffTagStripOffsets    = 273
+	tiffTagSamplesPerPixel = 277
+	tiffTagRowsPerStrip    = 278
+	tiffTagStripByteCounts = 279
+)
+
+// decodeTIFF decodes a baseline, uncompressed (Compression=1) 8-bit-per-
+// sample TIFF with 1 (grayscale) or 3 (RGB) samples per pixel - the subset
+// produced by dcraw/libcamera-still in "raw-ish" capture modes. LZW/JPEG
+// compression and higher bit depths are not supported.
+func decodeTIFF(r io.Reader) (image.Image, error) {
+	data, tags, err := readTIFF(r)
+	if err != nil {
+		return nil, err
+	}
+
+	width := int(tags.uint32(tiffTagImageWidth))
+	height := int(tags.uint32(tiffTagImageLength))
+	samples := int(tags.uint32WithDefault(tiffTagSamplesPerPixel, 1))
+	compression := tags.uint32WithDefault(tiffTagCompression, 1)
+	photometric := tags.uint32WithDefault(tiffTagPhotometric, 1)
+	rowsPerStrip := int(tags.uint32WithDefault(tiffTagRowsPerStrip, uint32(height)))
+
+	if compression != 1 {
+		return nil, fmt.Errorf("unsupported TIFF compression: %d", compression)
+	}
+	if bits := tags.values[tiffTagBitsPerSample]; len(bits) > 0 && bits[0] != 8 {
+		return nil, fmt.Errorf("unsupported TIFF bits per sample: %d", bits[0])
+	}
+	if samples != 1 && samples != 3 {
+		return nil, fmt.Errorf("unsupported TIFF samples per pixel: %d", samples)
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid TIFF dimensions: %dx%d", width, height)
+	}
+
+	offsets := tags.values[tiffTagStripOffsets]
+	byteCounts := tags.values[tiffTagStripByteCounts]
+	if len(offsets) == 0 {
+		return nil, fmt.Errorf("TIFF is missing StripOffsets")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for stripIdx, offset := range offsets {
+		stripStart := int(offset)
+		rowsInStrip := rowsPerStrip
+		firstRow := stripIdx * rowsPerStrip
+		if firstRow+rowsInStrip > height {
+			rowsInStrip = height - firstRow
+		}
+
+		var stripLen int
+		if stripIdx < len(byteCounts) {
+			stripLen = int(byteCounts[stripIdx])
+		} else {
+			stripLen = rowsInStrip * width * samples
+		}
+		if stripStart+stripLen > len(data) {
+			return nil, fmt.Errorf("TIFF strip %d extends past end of file", stripIdx)
+		}
+		strip := data[stripStart : stripStart+stripLen]
+
+		for row := 0; row < rowsInStrip; row++ {
+			y := firstRow + row
+			rowOffset := row * width * samples
+			for x := 0; x < width; x++ {
+				px := rowOffset + x*samples
+				if px+samples > len(strip) {
+					return nil, fmt.Errorf("TIFF strip %d truncated at row %d", stripIdx, row)
+				}
+				r, g, b := tiffPixelRGB(strip, px, samples, photometric)
+				img.SetNRGBA(x, y, color.NRGBA{R: r, G: g, B: b, A: 255})
+			}
+		}
+	}
+	return img, nil
+}
+
+func tiffPixelRGB(strip []byte, px, samples int, photometric uint32) (r, g, b uint8) {
+	if samples == 3 {
+		return strip[px], strip[px+1], strip[px+2]
+	}
+	v := strip[px]
+	if photometric == 0 { // WhiteIsZero
+		v = 255 - v
+	}
+	return v, v, v
+}
+
+func decodeTIFFConfig(r io.Reader) (image.Config, error) {
+	_, tags, err := readTIFF(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{
+		ColorModel: color.NRGBAModel,
+		Width:      int(tags.uint32(tiffTagImageWidth)),
+		Height:     int(tags.uint32(tiffTagImageLength)),
+	}, nil
+}
+
+// tiffTags holds the decoded values of every BYTE, SHORT or LONG IFD0
+// entry, keyed by tag ID. Each entry may hold more than one value (e.g. one
+// StripOffset per strip).
+type tiffTags struct {
+	values map[uint16][]uint32
+}
+
+func (t tiffTags) uint32(tag uint16) uint32 {
+	return t.uint32WithDefault(tag, 0)
+}
+
+func (t tiffTags) uint32WithDefault(tag uint16, def uint32) uint32 {
+	if v := t.values[tag]; len(v) > 0 {
+		return v[0]
+	}
+	return def
+}
+
+func readTIFF(r io.Reader) ([]byte, tiffTags, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, tiffTags{}, fmt.Errorf("failed to read TIFF: %v", err)
+	}
+	if len(data) < 8 {
+		return nil, tiffTags{}, fmt.Errorf("TIFF header truncated")
+	}
+
+	var bo binary.ByteOrder
+	switch {
+	case data[0] == 'I' && data[1] == 'I':
+		bo = binary.LittleEndian
+	case data[0] == 'M' && data[1] == 'M':
+		bo = binary.BigEndian
+	default:
+		return nil, tiffTags{}, fmt.Errorf("not a TIFF file")
+	}
+
+	ifdOffset := bo.Uint32(data[4:8])
+	tags, err := parseTIFFIFD(data, int(ifdOffset), bo)
+	return data, tags, err
+}
+
+func parseTIFFIFD(data []byte, offset int, bo binary.ByteOrder) (tiffTags, error) {
+	if offset+2 > len(data) {
+		return tiffTags{}, fmt.Errorf("TIFF IFD offset out of range")
+	}
+	entryCount := int(bo.Uint16(data[offset : offset+2]))
+	tags := tiffTags{values: make(map[uint16][]uint32, entryCount)}
+
+	for i := 0; i < entryCount; i++ {
+		entryOffset := offset + 2 + i*12
+		if entryOffset+12 > len(data) {
+			return tiffTags{}, fmt.Errorf("TIFF IFD entry %d out of range", i)
+		}
+		entry := data[entryOffset : entryOffset+12]
+		tag := bo.Uint16(entry[0:2])
+		typ := bo.Uint16(entry[2:4])
+		count := bo.Uint32(entry[4:8])
+
+		// Entries of other types (ASCII, RATIONAL, ...) are skipped: none of
+		// the tags decodeTIFF reads use them, but baseline files carry some
+		// (e.g. XResolution).
+		if tiffTypeSize(typ) == 0 {
+			continue
+		}
+
+		values, err := readTIFFValues(data, entry[8:12], typ, count, bo)
+		if err != nil {
+			return tiffTags{}, fmt.Errorf("TIFF tag %d: %v", tag, err)
+		}
+		tags.values[tag] = values
+	}
+	return tags, nil
+}
+
+// readTIFFValues decodes an IFD entry's count values of type typ, either
+// packed directly into the 4-byte valueField or (when they don't fit)
+// stored at the offset valueField encodes.
+func readTIFFValues(data []byte, valueField []byte, typ uint16, count uint32, bo binary.ByteOrder) ([]uint32, error) {
+	elemSize := tiffTypeSize(typ)
+	if elemSize == 0 {
+		return nil, fmt.Errorf("unsupported TIFF field type: %d", typ)
+	}
+	totalSize := int(count) * elemSize
+
+	src := valueField
+	if totalSize > 4 {
+		offset := int(bo.Uint32(valueField))
+		if offset+totalSize > len(data) {
+			return nil, fmt.Errorf("value data out of range")
+		}
+		src = data[offset : offset+totalSize]
+	}
+
+	values := make([]uint32, count)
+	for i := 0; i < int(count); i++ {
+		off := i * elemSize
+		switch typ {
+		case 1: // BYTE
+			values[i] = uint32(src[off])
+		case 3: // SHORT
+			values[i] = uint32(bo.Uint16(src[off : off+2]))
+		case 4: // LONG
+			values[i] = bo.Uint32(src[off : off+4])
+		}
+	}
+	return values, nil
+}
+
+func tiffTypeSize(typ uint16) int {
+	switch typ {
+	case 1: // BYTE
+		return 1
+	case 3: // SHORT
+		return 2
+	case 4: // LONG
+		return 4
+	default:
+		return 0
+	}
+}
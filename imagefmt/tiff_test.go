@@ -0,0 +1,81 @@
+package imagefmt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"testing"
+)
+
+// buildTIFFRGB encodes a tiny little-endian, single-strip, uncompressed
+// 8-bit RGB TIFF by hand, so decodeTIFF can be tested without a golden file
+// on disk.
+func buildTIFFRGB(t *testing.T, width, height int, pixels []byte) []byte {
+	t.Helper()
+
+	const numEntries = 8
+	ifdOffset := 8
+	stripDataOffset := ifdOffset + 2 + numEntries*12 + 4
+
+	buf := make([]byte, stripDataOffset+len(pixels))
+	bo := binary.LittleEndian
+
+	buf[0], buf[1] = 'I', 'I'
+	bo.PutUint16(buf[2:4], 42)
+	bo.PutUint32(buf[4:8], uint32(ifdOffset))
+
+	bo.PutUint16(buf[ifdOffset:ifdOffset+2], numEntries)
+
+	writeEntry := func(i int, tag, typ uint16, count, value uint32) {
+		off := ifdOffset + 2 + i*12
+		bo.PutUint16(buf[off:off+2], tag)
+		bo.PutUint16(buf[off+2:off+4], typ)
+		bo.PutUint32(buf[off+4:off+8], count)
+		bo.PutUint32(buf[off+8:off+12], value)
+	}
+
+	writeEntry(0, tiffTagImageWidth, 3, 1, uint32(width))
+	writeEntry(1, tiffTagImageLength, 3, 1, uint32(height))
+	writeEntry(2, tiffTagBitsPerSample, 3, 1, 8)
+	writeEntry(3, tiffTagCompression, 3, 1, 1)
+	writeEntry(4, tiffTagPhotometric, 3, 1, 2)
+	writeEntry(5, tiffTagStripOffsets, 4, 1, uint32(stripDataOffset))
+	writeEntry(6, tiffTagSamplesPerPixel, 3, 1, 3)
+	writeEntry(7, tiffTagRowsPerStrip, 3, 1, uint32(height))
+
+	copy(buf[stripDataOffset:], pixels)
+	return buf
+}
+
+func TestDecodeTIFFRGB(t *testing.T) {
+	pixels := []byte{
+		200, 10, 10, 10, 10, 200,
+		10, 200, 10, 50, 50, 50,
+	}
+	data := buildTIFFRGB(t, 2, 2, pixels)
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("image.Decode returned an error: %v", err)
+	}
+	if format != "tiff" {
+		t.Errorf("expected format tiff, got %s", format)
+	}
+
+	r, g, b, _ := img.At(0, 0).RGBA()
+	if uint8(r>>8) != 200 || uint8(g>>8) != 10 || uint8(b>>8) != 10 {
+		t.Errorf("pixel (0,0) = (%d,%d,%d), want (200,10,10)", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestDecodeTIFFRejectsCompression(t *testing.T) {
+	data := buildTIFFRGB(t, 1, 1, []byte{1, 2, 3})
+	// Compression entry is the 4th (index 3); its value lives in the last
+	// 4 bytes of that 12-byte entry.
+	entryOffset := 8 + 2 + 3*12
+	binary.LittleEndian.PutUint32(data[entryOffset+8:entryOffset+12], 5) // LZW
+
+	if _, err := decodeTIFF(bytes.NewReader(data)); err == nil {
+		t.Error("expected an error for compressed TIFF, got nil")
+	}
+}
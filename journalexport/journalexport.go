@@ -0,0 +1,65 @@
+// Package journalexport writes records in systemd's Journal Export Format
+// (https://systemd.io/JOURNAL_EXPORT_FORMATS/), so a stream of fields can be
+// piped into systemd-journal-remote or journalctl on a Pi running the
+// timelapse as a systemd unit.
+package journalexport
+
+import (
+	"encoding/binary"
+	"io"
+	"unicode/utf8"
+)
+
+// WriteField writes a single field. If value is valid UTF-8 and contains no
+// control characters other than tab, it's written in the simple text form
+// "NAME=value\n"; otherwise the binary form is used: "NAME\n" followed by a
+// little-endian uint64 length, the raw value bytes, and a trailing newline.
+func WriteField(w io.Writer, name, value string) error {
+	if isSimpleValue(value) {
+		_, err := io.WriteString(w, name+"="+value+"\n")
+		return err
+	}
+
+	if _, err := io.WriteString(w, name+"\n"); err != nil {
+		return err
+	}
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(value)))
+	if _, err := w.Write(length[:]); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, value); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteEntry writes every field in fields via WriteField, then terminates
+// the record with the blank line the Journal Export Format requires between
+// entries. Map iteration order means field order within an entry isn't
+// stable, which the format doesn't require.
+func WriteEntry(w io.Writer, fields map[string]string) error {
+	for name, value := range fields {
+		if err := WriteField(w, name, value); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func isSimpleValue(value string) bool {
+	if !utf8.ValidString(value) {
+		return false
+	}
+	for _, r := range value {
+		if r == '\t' {
+			continue
+		}
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package journalexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+func TestWriteFieldASCII(t *testing.T) {
+	var buf bytes.Buffer
+	ensure.Nil(t, WriteField(&buf, "MESSAGE", "capture succeeded"))
+	ensure.DeepEqual(t, "MESSAGE=capture succeeded\n", buf.String())
+}
+
+func TestWriteFieldEmbeddedNewlineUsesBinaryForm(t *testing.T) {
+	var buf bytes.Buffer
+	value := "line one\nline two"
+	ensure.Nil(t, WriteField(&buf, "MESSAGE", value))
+
+	ensure.True(t, bytes.HasPrefix(buf.Bytes(), []byte("MESSAGE\n")))
+	rest := buf.Bytes()[len("MESSAGE\n"):]
+	ensure.True(t, len(rest) >= 8)
+	length := binary.LittleEndian.Uint64(rest[:8])
+	ensure.DeepEqual(t, uint64(len(value)), length)
+	ensure.DeepEqual(t, value, string(rest[8:8+length]))
+	ensure.DeepEqual(t, byte('\n'), rest[8+length])
+}
+
+func TestWriteFieldInvalidUTF8(t *testing.T) {
+	var buf bytes.Buffer
+	value := string([]byte{0xff, 0xfe, 0xfd})
+	ensure.Nil(t, WriteField(&buf, "PAYLOAD", value))
+
+	ensure.True(t, bytes.HasPrefix(buf.Bytes(), []byte("PAYLOAD\n")))
+	rest := buf.Bytes()[len("PAYLOAD\n"):]
+	length := binary.LittleEndian.Uint64(rest[:8])
+	ensure.DeepEqual(t, uint64(len(value)), length)
+	ensure.DeepEqual(t, value, string(rest[8:8+length]))
+}
+
+func TestWriteEntryTerminatesWithBlankLine(t *testing.T) {
+	var buf bytes.Buffer
+	ensure.Nil(t, WriteEntry(&buf, map[string]string{"MESSAGE": "hello"}))
+	ensure.DeepEqual(t, "MESSAGE=hello\n\n", buf.String())
+}
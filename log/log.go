@@ -0,0 +1,60 @@
+// Package log wraps the standard logger with syzkaller-style verbosity
+// gating (see conf.Settings.Verbosity), so operators can silence
+// high-frequency detail like per-second sleep ticks while still seeing
+// capture events, by raising or lowering a single level instead of toggling
+// individual log lines on and off.
+package log
+
+import "log"
+
+// Level is a logging verbosity level, from Quiet (nothing but errors) to
+// Trace (everything).
+type Level int
+
+const (
+	Quiet Level = 0
+	Info  Level = 1
+	Debug Level = 2
+	Trace Level = 3
+)
+
+var verbosity = Info
+
+// SetVerbosity sets the global verbosity level. Infof/Debugf/Tracef calls
+// above it are dropped; Errorf/Fatalf always log.
+func SetVerbosity(level int) {
+	verbosity = Level(level)
+}
+
+// Infof logs a normal operational message, e.g. a capture completing.
+func Infof(format string, args ...interface{}) {
+	logAt(Info, format, args...)
+}
+
+// Debugf logs per-operation detail that's useful while troubleshooting but
+// noisy in normal operation.
+func Debugf(format string, args ...interface{}) {
+	logAt(Debug, format, args...)
+}
+
+// Tracef logs the highest-frequency detail, e.g. per-second polling ticks.
+func Tracef(format string, args ...interface{}) {
+	logAt(Trace, format, args...)
+}
+
+// Errorf logs an error message regardless of verbosity.
+func Errorf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+// Fatalf logs a message regardless of verbosity, then exits the process.
+func Fatalf(format string, args ...interface{}) {
+	log.Fatalf(format, args...)
+}
+
+func logAt(level Level, format string, args ...interface{}) {
+	if verbosity < level {
+		return
+	}
+	log.Printf(format, args...)
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func captureOutput(t *testing.T, fn func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	prevOutput := log.Writer()
+	prevFlags := log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(prevOutput)
+		log.SetFlags(prevFlags)
+		SetVerbosity(int(Info))
+	}()
+
+	fn()
+	return buf.String()
+}
+
+func TestInfofHiddenAtQuiet(t *testing.T) {
+	SetVerbosity(int(Quiet))
+	output := captureOutput(t, func() {
+		Infof("hello %s", "world")
+	})
+	if output != "" {
+		t.Errorf("expected no output at Quiet, got %q", output)
+	}
+}
+
+func TestInfofShownAtInfo(t *testing.T) {
+	SetVerbosity(int(Info))
+	output := captureOutput(t, func() {
+		Infof("hello %s", "world")
+	})
+	if !strings.Contains(output, "hello world") {
+		t.Errorf("expected Infof output at Info, got %q", output)
+	}
+}
+
+func TestDebugfHiddenBelowDebug(t *testing.T) {
+	SetVerbosity(int(Info))
+	output := captureOutput(t, func() {
+		Debugf("sleeping")
+	})
+	if output != "" {
+		t.Errorf("expected Debugf to be hidden below Debug, got %q", output)
+	}
+}
+
+func TestTracefShownAtTrace(t *testing.T) {
+	SetVerbosity(int(Trace))
+	output := captureOutput(t, func() {
+		Tracef("tick")
+	})
+	if !strings.Contains(output, "tick") {
+		t.Errorf("expected Tracef output at Trace, got %q", output)
+	}
+}
+
+func TestErrorfAlwaysShown(t *testing.T) {
+	SetVerbosity(int(Quiet))
+	output := captureOutput(t, func() {
+		Errorf("boom")
+	})
+	if !strings.Contains(output, "boom") {
+		t.Errorf("expected Errorf to log regardless of verbosity, got %q", output)
+	}
+}
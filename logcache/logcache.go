@@ -0,0 +1,111 @@
+// Package logcache buffers recent log lines in memory, modeled on
+// syzkaller's caching logger, so the frontend can fetch recent log output
+// without rereading the (potentially large) log file from the Pi's SD card.
+package logcache
+
+import (
+	"strings"
+	"sync"
+)
+
+// Cache is a fixed-size ring buffer of log lines, additionally bounded by a
+// byte budget. It implements io.Writer so it can be passed to
+// io.MultiWriter alongside the real log destination.
+type Cache struct {
+	mu       sync.Mutex
+	lines    []string
+	maxLines int
+	maxBytes int
+	bytes    int
+	cachePos int
+	full     bool
+}
+
+// EnableCaching allocates a ring buffer holding at most maxLines lines,
+// evicting the oldest line whenever maxLines or maxBytes is exceeded.
+func EnableCaching(maxLines, maxBytes int) *Cache {
+	if maxLines <= 0 {
+		maxLines = 1
+	}
+	return &Cache{lines: make([]string, maxLines), maxLines: maxLines, maxBytes: maxBytes}
+}
+
+// Write implements io.Writer, pushing p into the ring as a single entry.
+func (c *Cache) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	line := string(p)
+	if c.full {
+		c.bytes -= len(c.lines[c.cachePos])
+	}
+	c.lines[c.cachePos] = line
+	c.bytes += len(line)
+	c.cachePos = (c.cachePos + 1) % c.maxLines
+	if c.cachePos == 0 {
+		c.full = true
+	}
+
+	c.evictToByteBudget()
+	return len(p), nil
+}
+
+// evictToByteBudget clears the oldest remaining entries, starting right
+// after the line just written, until the cache fits within maxBytes.
+func (c *Cache) evictToByteBudget() {
+	if c.maxBytes <= 0 {
+		return
+	}
+	oldest := 0
+	if c.full {
+		oldest = c.cachePos
+	}
+	newest := (c.cachePos - 1 + c.maxLines) % c.maxLines
+	for c.bytes > c.maxBytes && oldest != newest {
+		if c.lines[oldest] != "" {
+			c.bytes -= len(c.lines[oldest])
+			c.lines[oldest] = ""
+		}
+		oldest = (oldest + 1) % c.maxLines
+	}
+}
+
+// CachedOutput concatenates every cached line in chronological order.
+func (c *Cache) CachedOutput() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var b strings.Builder
+	start := 0
+	if c.full {
+		start = c.cachePos
+	}
+	for i := 0; i < c.maxLines; i++ {
+		b.WriteString(c.lines[(start+i)%c.maxLines])
+	}
+	return b.String()
+}
+
+// Lines returns at most n of the most recently cached, non-empty log lines
+// in chronological order. A non-positive n returns every cached line.
+func (c *Cache) Lines(n int) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := c.cachePos
+	if c.full {
+		total = c.maxLines
+	}
+	if n <= 0 || n > total {
+		n = total
+	}
+
+	start := (c.cachePos - n + c.maxLines) % c.maxLines
+	out := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if line := c.lines[(start+i)%c.maxLines]; line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package logcache
+
+import "testing"
+
+func TestCacheEvictsOldestByLineCount(t *testing.T) {
+	c := EnableCaching(2, 0)
+	c.Write([]byte("one"))
+	c.Write([]byte("two"))
+	c.Write([]byte("three"))
+
+	got := c.Lines(0)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 lines after eviction, got %d: %+v", len(got), got)
+	}
+	if got[0] != "two" || got[1] != "three" {
+		t.Errorf("expected oldest line to be evicted, got %+v", got)
+	}
+}
+
+func TestCacheEvictsByByteBudget(t *testing.T) {
+	c := EnableCaching(10, 8)
+	c.Write([]byte("aaaa"))
+	c.Write([]byte("bbbb"))
+	c.Write([]byte("cccc"))
+
+	got := c.Lines(0)
+	if len(got) != 2 {
+		t.Fatalf("expected only 2 lines to fit the byte budget, got %d: %+v", len(got), got)
+	}
+	if got[0] != "bbbb" || got[1] != "cccc" {
+		t.Errorf("expected oldest line to be evicted for budget, got %+v", got)
+	}
+}
+
+func TestCacheOrderingAfterWrap(t *testing.T) {
+	c := EnableCaching(3, 0)
+	c.Write([]byte("a"))
+	c.Write([]byte("b"))
+	c.Write([]byte("c"))
+	c.Write([]byte("d"))
+
+	if output := c.CachedOutput(); output != "bcd" {
+		t.Errorf("expected cached output in chronological order after wrap, got %q", output)
+	}
+}
+
+func TestCacheLinesRespectsRequestedCount(t *testing.T) {
+	c := EnableCaching(10, 0)
+	c.Write([]byte("a"))
+	c.Write([]byte("b"))
+	c.Write([]byte("c"))
+
+	got := c.Lines(2)
+	if len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Errorf("expected last 2 lines, got %+v", got)
+	}
+}
+
+func TestCacheLinesBeforeFull(t *testing.T) {
+	c := EnableCaching(5, 0)
+	c.Write([]byte("a"))
+	c.Write([]byte("b"))
+
+	got := c.Lines(0)
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected only the written lines, got %+v", got)
+	}
+}
@@ -1,18 +1,27 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/events"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/hkcam"
+	vlog "github.com/ingojaeckel/go-raspberry-pi-timelapse/log"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/logcache"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/metrics"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/monitoring"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/publish"
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/rest"
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/timelapse"
-	"goji.io"
 	"goji.io/pat"
 )
 
@@ -31,6 +40,10 @@ func main() {
 	logToFile := flag.Bool("logToFile", conf.DefaultLogToFile, "Toggle to enable logging to a file on disk instead of stdout. Logging to a file is recommended for long term operation.")
 	storageAddress := flag.String("storageFolder", conf.DefaultStorageFolder, "Folder for storage of timelapse pictures.")
 	secondsBetweenCaptures := flag.Int("secondsBetweenCaptures", conf.DefaultSecondsBetweenCaptures, "Number of seconds between captures")
+	verbosity := flag.Int("v", conf.DefaultVerbosity, "Logging verbosity: 0=quiet, 1=info, 2=debug, 3=trace.")
+	journalExportFlag := flag.String("journalExport", "", "Path to write monitoring stats to in systemd Journal Export Format (https://systemd.io/JOURNAL_EXPORT_FORMATS/), or - for stdout. Disabled by default.")
+	apiToken := flag.String("apiToken", "", "Bearer token required on every API request (except federation/login endpoints). Persisted for reuse across restarts; if empty, falls back to a previously stored token, else the API requires no authentication.")
+	rateLimitPerMinute := flag.Int("rateLimitPerMinute", 0, "Maximum API requests per client address (not per authenticated identity) per rolling minute. 0 disables rate limiting.")
 	flag.Parse()
 
 	if *versionFlag {
@@ -39,13 +52,14 @@ func main() {
 	}
 	
 	// Validate CLI flags before applying them
-	if err := validateCLIFlags(secondsBetweenCaptures); err != nil {
+	if err := validateCLIFlags(secondsBetweenCaptures, verbosity); err != nil {
 		log.Fatalf("Invalid CLI flags: %s", err.Error())
 		return
 	}
 	
 	conf.OverrideDefaultConfig(listenAddress, storageAddress, logToFile, secondsBetweenCaptures)
-	if err := initLogging(); err != nil {
+	logCache, err := initLogging()
+	if err != nil {
 		log.Fatalf("Failed to initialize logging. Unable to start. Cause: %s", err.Error())
 		return
 	}
@@ -57,13 +71,53 @@ func main() {
 	}
 	
 	// Apply CLI overrides with proper priority: CLI flags override persisted settings
-	*initialSettings = initialSettings.ApplyCLIOverrides(secondsBetweenCaptures)
-	
+	*initialSettings = initialSettings.ApplyCLIOverrides(secondsBetweenCaptures, verbosity)
+	vlog.SetVerbosity(initialSettings.Verbosity)
+
+	if initialSettings.PublishEnabled {
+		if err := publish.EnsureKeyPair(initialSettings); err != nil {
+			log.Printf("Failed to set up ActivityPub publishing key: %s\n", err.Error())
+		}
+	}
+	metrics.UpdateConfigGauges(*initialSettings)
+	timelapse.ConfigurePacerFromSettings(*initialSettings)
+
+	if initialSettings.HomeKitEnabled {
+		startHomeKitAccessory(*initialSettings)
+	}
+
+	eventer, err := events.New(initialSettings.EventsBackend, events.DefaultLogFilePath, initialSettings.EventsMemoryCapacity)
+	if err != nil {
+		log.Printf("Failed to set up events backend '%s', falling back to an in-memory one: %s\n", initialSettings.EventsBackend, err.Error())
+		eventer = events.NewMemoryEventer(conf.DefaultEventsMemoryCapacity)
+	}
+
 	log.Printf("Settings:       %s\n", *initialSettings)
 	log.Printf("Listen address: %s\n", conf.ListenAddress)
 
-	mux := goji.NewMux()
-	mux.Use(func(inner http.Handler) http.Handler {
+	var monitorOpts []monitoring.Option
+	if journalExportDest, err := openJournalExportDest(*journalExportFlag); err != nil {
+		log.Printf("Failed to open journal export destination '%s': %s\n", *journalExportFlag, err.Error())
+	} else if journalExportDest != nil {
+		monitorOpts = append(monitorOpts, monitoring.WithJournalExport(journalExportDest))
+	}
+	monitor, err := monitoring.New(eventer, monitorOpts...)
+	if err != nil {
+		log.Printf("Error creating new monitor instance: %s\n", err.Error())
+	}
+
+	authenticator, err := buildAuthenticator(*apiToken)
+	if err != nil {
+		log.Printf("Failed to set up API authentication, falling back to no authentication: %s\n", err.Error())
+		authenticator = rest.NoopAuth{}
+	}
+
+	api := rest.NewAPI(rest.APIOptions{
+		Authenticator:      authenticator,
+		AllowedOrigins:     splitAndTrim(initialSettings.AllowedOrigins),
+		RateLimitPerMinute: *rateLimitPerMinute,
+	})
+	api.Use(func(inner http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			before := time.Now()
 			inner.ServeHTTP(w, r)
@@ -72,59 +126,150 @@ func main() {
 		})
 	})
 
-	// Frontend APIs
-	mux.Handle(pat.Get("/static/*"), http.StripPrefix("/static/", http.FileServer(http.FS(content))))
+	// Frontend assets and federation endpoints are reached by browsers and
+	// remote ActivityPub servers that don't hold our API token, so they
+	// bypass the capability model entirely.
+	api.Handle(pat.Get("/static/*"), http.StripPrefix("/static/", http.FileServer(http.FS(content))))
 	// Redirect to frontend. Ideally this could be built in a way it can be hosted on or closer to /.
-	mux.Handle(pat.Get("/"), http.RedirectHandler("/static/frontend/build/index.html", http.StatusMovedPermanently))
-
-	// Backend APIs (should only be called by frontend code)
-	mux.HandleFunc(pat.Get("/capture"), func(w http.ResponseWriter, _ *http.Request) {
-		rest.Capture(w, initialSettings)
-	})
+	api.Handle(pat.Get("/"), http.RedirectHandler("/static/frontend/build/index.html", http.StatusMovedPermanently))
+	api.Handle(pat.Get("/version"), http.HandlerFunc(rest.MakeGetVersionFn(version)))
+	api.Handle(pat.Get("/actor"), http.HandlerFunc(publish.GetActor))
+	api.Handle(pat.Post("/inbox"), http.HandlerFunc(publish.PostInbox))
+	api.Handle(pat.Get("/outbox"), http.HandlerFunc(publish.GetOutbox))
+	api.Handle(pat.Get("/.well-known/webfinger"), http.HandlerFunc(publish.GetWebfinger))
+	// Login bypasses the capability model too (that's the point: it issues
+	// the credential), but unlike the endpoints above it's a target for
+	// credential guessing, so it still goes through the rate limiter.
+	api.HandleLimited(pat.Post("/auth/login"), rest.MakeLoginFn(authenticator))
 
 	configUpdatedChan := make(chan conf.Settings)
+	// Pick up edits to the settings file made outside this process (or by a
+	// REST write, harmlessly re-publishing the same settings) without
+	// requiring a restart.
+	conf.NewWatcher(configUpdatedChan)
+
+	api.Register([]rest.Route{
+		{Method: http.MethodGet, Path: "/me", Handler: rest.GetMe},
+		{Method: http.MethodGet, Path: "/capture", Capability: rest.CapCapture, Handler: func(w http.ResponseWriter, _ *http.Request) {
+			rest.Capture(w, initialSettings)
+		}},
+		{Method: http.MethodGet, Path: "/logs", Capability: rest.CapArchiveRead, Handler: rest.GetLogs},
+		{Method: http.MethodGet, Path: "/logs/recent", Capability: rest.CapArchiveRead, Handler: rest.MakeGetRecentLogsFn(logCache)},
+		{Method: http.MethodGet, Path: "/photos", Capability: rest.CapArchiveRead, Handler: rest.GetPhotos},
+		{Method: http.MethodGet, Path: "/monitoring", Capability: rest.CapArchiveRead, Handler: rest.GetMonitoring},
+		{Method: http.MethodGet, Path: "/metrics", Capability: rest.CapArchiveRead, Handler: rest.GetMetrics},
+		{Method: http.MethodGet, Path: "/events", Capability: rest.CapArchiveRead, Handler: rest.MakeGetEventsFn(eventer)},
+		{Method: http.MethodGet, Path: "/file", Capability: rest.CapArchiveRead, Handler: rest.GetFiles},
+		{Method: http.MethodGet, Path: "/file/delete", Capability: rest.CapConfigWrite, Handler: rest.DeleteFiles},
+		{Method: http.MethodGet, Path: "/file/last", Capability: rest.CapArchiveRead, Handler: rest.GetMostRecentFile},
+		{Method: http.MethodGet, Path: "/file/:fileName/exif", Capability: rest.CapArchiveRead, Handler: rest.GetFileExif},
+		{Method: http.MethodGet, Path: "/file/:fileName", Capability: rest.CapArchiveRead, Handler: rest.GetFile},
+		{Method: http.MethodGet, Path: "/archive", Capability: rest.CapArchiveRead, Handler: rest.GetArchive},
+		{Method: http.MethodGet, Path: "/archive/zip", Capability: rest.CapArchiveRead, Handler: rest.GetArchiveZip},
+		{Method: http.MethodGet, Path: "/archive/tar", Capability: rest.CapArchiveRead, Handler: rest.GetArchiveTar},
+		{Method: http.MethodGet, Path: "/archive/tar.gz", Capability: rest.CapArchiveRead, Handler: rest.GetArchiveTarGz},
+		{Method: http.MethodGet, Path: "/archive/progress", Capability: rest.CapArchiveRead, Handler: rest.GetArchiveProgress},
+		{Method: http.MethodGet, Path: "/archive/mp4", Capability: rest.CapArchiveRead, Handler: rest.GetArchiveMp4},
+		{Method: http.MethodGet, Path: "/archive/mp4/progress", Capability: rest.CapArchiveRead, Handler: rest.GetArchiveMp4Progress},
+		{Method: http.MethodPost, Path: "/video/render", Capability: rest.CapArchiveRead, Handler: rest.PostVideoRender},
+		{Method: http.MethodGet, Path: "/video/jobs/:id", Capability: rest.CapArchiveRead, Handler: rest.GetVideoJob},
+		{Method: http.MethodGet, Path: "/video/:id", Capability: rest.CapArchiveRead, Handler: rest.GetVideo},
+		{Method: http.MethodGet, Path: "/admin/:command", Capability: rest.CapAdminExec, Handler: rest.Admin},
+		{Method: http.MethodGet, Path: "/configuration", Capability: rest.CapArchiveRead, Handler: rest.GetConfiguration},
+		{Method: http.MethodOptions, Path: "/configuration", Handler: rest.GetConfiguration},
+		{Method: http.MethodPost, Path: "/configuration", Capability: rest.CapConfigWrite, Handler: rest.MakeUpdateConfigurationFn(configUpdatedChan)},
+	})
 
-	mux.HandleFunc(pat.Get("/logs"), rest.GetLogs)
-	mux.HandleFunc(pat.Get("/photos"), rest.GetPhotos)
-	mux.HandleFunc(pat.Get("/monitoring"), rest.GetMonitoring)
-	mux.HandleFunc(pat.Get("/file"), rest.GetFiles)
-	mux.HandleFunc(pat.Get("/file/delete"), rest.DeleteFiles)
-	mux.HandleFunc(pat.Get("/file/last"), rest.GetMostRecentFile)
-	mux.HandleFunc(pat.Get("/file/:fileName"), rest.GetFile)
-	mux.HandleFunc(pat.Get("/archive/zip"), rest.GetArchiveZip)
-	mux.HandleFunc(pat.Get("/archive/tar"), rest.GetArchiveTar)
-	mux.HandleFunc(pat.Get("/admin/:command"), rest.Admin)
-	mux.HandleFunc(pat.Get("/configuration"), rest.GetConfiguration)
-	mux.HandleFunc(pat.Options("/configuration"), rest.GetConfiguration)
-	mux.HandleFunc(pat.Post("/configuration"), rest.MakeUpdateConfigurationFn(configUpdatedChan))
-	mux.HandleFunc(pat.Get("/version"), rest.MakeGetVersionFn(version))
-
-	t, err := timelapse.New(conf.StorageFolder, *initialSettings, configUpdatedChan)
+	t, err := timelapse.New(conf.StorageFolder, *initialSettings, configUpdatedChan, eventer)
 	if err != nil {
 		log.Printf("Error creating new timelapse instance: %s\n", err.Error())
 		// Continue starting app regardless
 	} else {
+		if monitor != nil {
+			t.Monitor = monitor
+		}
 		// Start capturing since there were no issues
 		t.CapturePeriodically()
 	}
 
 	log.Println("Listening...")
-	if err := http.ListenAndServe(conf.ListenAddress, mux); err != nil {
+	if err := http.ListenAndServe(conf.ListenAddress, api.Handler()); err != nil {
 		log.Fatal("Failed start: ", err.Error())
 	}
 }
 
-func initLogging() error {
+// buildAuthenticator uses tokenFlag if set (persisting it for reuse across
+// restarts), else falls back to a previously persisted token. An empty
+// result after both means the API requires no authentication, matching
+// this server's historical behavior.
+func buildAuthenticator(tokenFlag string) (rest.Authenticator, error) {
+	token := tokenFlag
+	if token != "" {
+		if err := conf.StoreAPIToken(token); err != nil {
+			return rest.NoopAuth{}, err
+		}
+	} else {
+		stored, exists, err := conf.LoadAPIToken()
+		if err != nil {
+			return rest.NoopAuth{}, err
+		}
+		if exists {
+			token = stored
+		}
+	}
+	if token == "" {
+		return rest.NoopAuth{}, nil
+	}
+	return rest.TokenAuth{Token: token}, nil
+}
+
+// splitAndTrim splits a comma-separated list into its trimmed, non-empty
+// elements, e.g. for conf.Settings.AllowedOrigins.
+func splitAndTrim(list string) []string {
+	if list == "" {
+		return nil
+	}
+	var out []string
+	for _, item := range strings.Split(list, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
+
+// initLogging points the standard logger at stdout or, if enabled, a file on
+// disk, and mirrors every log line into a bounded in-memory cache so the
+// frontend can fetch recent output without rereading that file.
+func initLogging() (*logcache.Cache, error) {
+	dest := io.Writer(os.Stdout)
 	if conf.LogToFile {
 		f, err := os.OpenFile(conf.LogFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		log.SetOutput(f)
+		dest = f
 	}
+
+	cache := logcache.EnableCaching(conf.DefaultLogCacheMaxLines, conf.DefaultLogCacheMaxBytes)
+	log.SetOutput(io.MultiWriter(dest, cache))
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.Printf("Version %s started at %s\n", version, time.Now())
-	return nil
+	return cache, nil
+}
+
+// openJournalExportDest opens the destination for --journalExport: "-" means
+// stdout, an empty string disables the feature (returns a nil Writer), and
+// anything else is treated as a file path to append to.
+func openJournalExportDest(path string) (io.Writer, error) {
+	switch path {
+	case "":
+		return nil, nil
+	case "-":
+		return os.Stdout, nil
+	default:
+		return os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	}
 }
 
 func initVersion() {
@@ -136,9 +281,34 @@ func initVersion() {
 	}
 }
 
-func validateCLIFlags(secondsBetweenCaptures *int) error {
+// startHomeKitAccessory publishes the capture device as a HomeKit IP camera
+// accessory (see the hkcam package) in the background. This only has any
+// effect in binaries built with the "homekit" tag; otherwise hkcam.New
+// fails and is logged, matching this server's general "degrade, don't
+// crash" approach to optional subsystems.
+func startHomeKitAccessory(settings conf.Settings) {
+	server, err := hkcam.New(hkcam.Options{
+		StorageFolder: conf.StorageFolder,
+		BridgeName:    settings.HomeKitBridgeName,
+		PIN:           settings.HomeKitPIN,
+	})
+	if err != nil {
+		log.Printf("HomeKit camera accessory disabled: %s\n", err.Error())
+		return
+	}
+	go func() {
+		if err := server.Run(context.Background()); err != nil {
+			log.Printf("HomeKit camera accessory stopped: %s\n", err.Error())
+		}
+	}()
+}
+
+func validateCLIFlags(secondsBetweenCaptures *int, verbosity *int) error {
 	if secondsBetweenCaptures != nil && *secondsBetweenCaptures < conf.MinSecondsBetweenCaptures {
 		return fmt.Errorf("secondsBetweenCaptures must be at least %d seconds to allow sufficient exposure time (got %d)", conf.MinSecondsBetweenCaptures, *secondsBetweenCaptures)
 	}
+	if verbosity != nil && (*verbosity < conf.MinVerbosity || *verbosity > conf.MaxVerbosity) {
+		return fmt.Errorf("verbosity must be between %d and %d (got %d)", conf.MinVerbosity, conf.MaxVerbosity, *verbosity)
+	}
 	return nil
 }
@@ -0,0 +1,118 @@
+// Package metrics exposes capture, detection, and system telemetry as
+// Prometheus metrics so a fleet of timelapse devices can be scraped by a
+// central Grafana dashboard instead of polled one at a time via /monitoring.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/detection"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	capturesAttempted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "timelapse_captures_attempted_total",
+		Help: "Total number of capture attempts.",
+	})
+	capturesSucceeded = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "timelapse_captures_succeeded_total",
+		Help: "Total number of captures that completed successfully.",
+	})
+	capturesFailed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "timelapse_captures_failed_total",
+		Help: "Total number of captures that failed.",
+	})
+	detectionLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "timelapse_detection_latency_milliseconds",
+		Help:    "Object detection latency, from detection.DetectionResult.LatencyMs.",
+		Buckets: prometheus.ExponentialBuckets(10, 2, 12), // 10ms .. ~20s
+	})
+	objectsDetected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "timelapse_objects_detected_total",
+		Help: "Total number of detected objects, by class.",
+	}, []string{"class"})
+	secondsBetweenCaptures = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "timelapse_seconds_between_captures",
+		Help: "Configured number of seconds between captures.",
+	})
+	photoResolutionWidth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "timelapse_photo_resolution_width_pixels",
+		Help: "Configured photo resolution width.",
+	})
+	photoResolutionHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "timelapse_photo_resolution_height_pixels",
+		Help: "Configured photo resolution height.",
+	})
+	detectionCacheResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "timelapse_detection_cache_results_total",
+		Help: "Total number of detection.CachingAnalyzer lookups, by outcome (hit, near_hit, miss).",
+	}, []string{"outcome"})
+
+	registry = prometheus.NewRegistry()
+)
+
+func init() {
+	registry.MustRegister(
+		capturesAttempted,
+		capturesSucceeded,
+		capturesFailed,
+		detectionLatency,
+		objectsDetected,
+		secondsBetweenCaptures,
+		photoResolutionWidth,
+		photoResolutionHeight,
+		detectionCacheResults,
+	)
+}
+
+// RecordCaptureAttempt increments the capture-attempted counter. Call once
+// per capture, before the outcome is known.
+func RecordCaptureAttempt() {
+	capturesAttempted.Inc()
+}
+
+// RecordCaptureOutcome increments the succeeded or failed counter depending
+// on whether the capture returned an error.
+func RecordCaptureOutcome(err error) {
+	if err != nil {
+		capturesFailed.Inc()
+	} else {
+		capturesSucceeded.Inc()
+	}
+}
+
+// RecordDetection observes a completed detection's latency and tallies its
+// detected objects by class.
+func RecordDetection(result *detection.DetectionResult) {
+	if result == nil {
+		return
+	}
+	detectionLatency.Observe(float64(result.LatencyMs))
+	for _, obj := range result.Details {
+		objectsDetected.WithLabelValues(obj.Class).Inc()
+	}
+}
+
+// RecordCacheResult tallies one CachingAnalyzer lookup outcome: "hit" for an
+// exact perceptual-hash match, "near_hit" for a match within the configured
+// Hamming threshold, or "miss" when analysis had to run.
+func RecordCacheResult(outcome string) {
+	detectionCacheResults.WithLabelValues(outcome).Inc()
+}
+
+// UpdateConfigGauges refreshes the gauges that mirror the current
+// configuration, so /metrics reflects settings changes without a restart.
+func UpdateConfigGauges(settings conf.Settings) {
+	secondsBetweenCaptures.Set(float64(settings.SecondsBetweenCaptures))
+	photoResolutionWidth.Set(float64(settings.PhotoResolutionWidth))
+	photoResolutionHeight.Set(float64(settings.PhotoResolutionHeight))
+}
+
+// Handler returns the http.Handler that serves the Prometheus text
+// exposition format for all metrics registered above.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/detection"
+)
+
+func TestRecordCaptureOutcome(t *testing.T) {
+	RecordCaptureAttempt()
+	RecordCaptureOutcome(nil)
+	RecordCaptureOutcome(errors.New("capture failed"))
+
+	body := scrape(t)
+	if !strings.Contains(body, "timelapse_captures_attempted_total 1") {
+		t.Errorf("expected attempted counter to be exported, got: %s", body)
+	}
+	if !strings.Contains(body, "timelapse_captures_succeeded_total 1") {
+		t.Errorf("expected succeeded counter to be exported, got: %s", body)
+	}
+	if !strings.Contains(body, "timelapse_captures_failed_total 1") {
+		t.Errorf("expected failed counter to be exported, got: %s", body)
+	}
+}
+
+func TestRecordDetection(t *testing.T) {
+	RecordDetection(&detection.DetectionResult{
+		LatencyMs: 42,
+		Details:   []detection.ObjectDetail{{Class: "cat"}, {Class: "cat"}},
+	})
+
+	body := scrape(t)
+	if !strings.Contains(body, `timelapse_objects_detected_total{class="cat"} 2`) {
+		t.Errorf("expected per-class object counter to be exported, got: %s", body)
+	}
+}
+
+func TestUpdateConfigGauges(t *testing.T) {
+	UpdateConfigGauges(conf.Settings{SecondsBetweenCaptures: 1800, PhotoResolutionWidth: 2186, PhotoResolutionHeight: 1642})
+
+	body := scrape(t)
+	if !strings.Contains(body, "timelapse_seconds_between_captures 1800") {
+		t.Errorf("expected seconds-between-captures gauge to be exported, got: %s", body)
+	}
+}
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, req)
+	return w.Body.String()
+}
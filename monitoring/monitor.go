@@ -1,17 +1,23 @@
 package monitoring
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/admin"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/events"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/journalexport"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/log"
 )
 
 const (
-	// DebugLogFile is the file where system monitoring debug info is stored
+	// DebugLogFile names the system-debug log file. Monitor does not write
+	// to it: events go to the events.Eventer passed to New, whose LogFile
+	// backend defaults to events.DefaultLogFilePath.
 	DebugLogFile = "system-debug.log"
 	// MonitoringInterval is how often to log system stats (5 minutes)
 	MonitoringInterval = 5 * time.Minute
@@ -32,36 +38,46 @@ type SystemStats struct {
 type Monitor struct {
 	startTime       time.Time
 	lastLogTime     time.Time
-	debugLogFile    *os.File
+	eventer         events.Eventer
 	dailyStartTime  time.Time
 	maxDailyRuntime int64
+	journalExport   io.Writer
 }
 
-// New creates a new system monitor
-func New() (*Monitor, error) {
-	f, err := os.OpenFile(DebugLogFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
-	if err != nil {
-		return nil, err
+// Option configures optional Monitor behavior. See WithJournalExport.
+type Option func(*Monitor)
+
+// WithJournalExport makes logStats additionally write each recorded
+// SystemStats as a systemd Journal Export Format entry (see the
+// journalexport package) to w, e.g. a file fed to systemd-journal-remote.
+func WithJournalExport(w io.Writer) Option {
+	return func(m *Monitor) {
+		m.journalExport = w
 	}
+}
 
+// New creates a new system monitor that records its events to eventer (see
+// the events package).
+func New(eventer events.Eventer, opts ...Option) (*Monitor, error) {
 	m := &Monitor{
 		startTime:      time.Now(),
 		lastLogTime:    time.Now(),
-		debugLogFile:   f,
+		eventer:        eventer,
 		dailyStartTime: time.Now(),
 	}
 
-	// Log startup event
-	m.logEvent("STARTUP", "System started")
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.logEvent(events.Startup, "System started", nil)
 	return m, nil
 }
 
-// Close closes the debug log file
+// Close records a shutdown event. Closing the underlying Eventer, if it owns
+// a resource like an open file, is the caller's responsibility.
 func (m *Monitor) Close() error {
-	m.logEvent("SHUTDOWN", fmt.Sprintf("System shutdown after %d seconds runtime", m.getRuntimeSeconds()))
-	if m.debugLogFile != nil {
-		return m.debugLogFile.Close()
-	}
+	m.logEvent(events.Shutdown, fmt.Sprintf("System shutdown after %d seconds runtime", m.getRuntimeSeconds()), nil)
 	return nil
 }
 
@@ -71,7 +87,9 @@ func (m *Monitor) PeriodicCheck() {
 
 	// Check if we've moved to a new day to reset daily runtime tracking
 	if now.Day() != m.dailyStartTime.Day() {
-		m.logEvent("DAILY_RUNTIME", fmt.Sprintf("Max runtime for previous day: %d seconds", m.maxDailyRuntime))
+		m.logEvent(events.DailyRuntime, fmt.Sprintf("Max runtime for previous day: %d seconds", m.maxDailyRuntime), map[string]string{
+			"runtimeSeconds": strconv.FormatInt(m.maxDailyRuntime, 10),
+		})
 		m.dailyStartTime = now
 		m.maxDailyRuntime = 0
 	}
@@ -110,36 +128,75 @@ func (m *Monitor) getRuntimeSeconds() int64 {
 	return int64(time.Since(m.startTime).Seconds())
 }
 
-// logStats writes system statistics to the debug log
+// logStats records an events.Stats event with the collected SystemStats, and
+// additionally exports it via journalexport if WithJournalExport was set.
 func (m *Monitor) logStats(stats SystemStats) {
-	jsonData, err := json.Marshal(stats)
-	if err != nil {
-		log.Printf("Error marshaling system stats: %s\n", err)
+	// FreeDiskSpace holds the full, multi-line "df -h" output; it goes in
+	// attrs/the freeDiskSpace journal field, not in message, so message stays
+	// a single line (a message with embedded newlines forces journalexport
+	// into its binary field encoding, and reads poorly in plain logs).
+	message := fmt.Sprintf("System stats recorded - Runtime: %ds, CPU temp: %s",
+		stats.RuntimeSeconds, stats.CpuTemperature)
+	attrs := map[string]string{
+		"runtimeSeconds": strconv.FormatInt(stats.RuntimeSeconds, 10),
+		"cpuTemperature": stats.CpuTemperature,
+		"gpuTemperature": stats.GpuTemperature,
+		"freeDiskSpace":  stats.FreeDiskSpace,
+		"systemClock":    stats.SystemClock,
+	}
+
+	m.logEvent(events.Stats, message, attrs)
+	m.exportToJournal(message, attrs)
+}
+
+// exportToJournal writes message and attrs as a single systemd Journal
+// Export Format entry to m.journalExport, if configured.
+func (m *Monitor) exportToJournal(message string, attrs map[string]string) {
+	if m.journalExport == nil {
 		return
 	}
 
-	logLine := fmt.Sprintf("[STATS] %s\n", string(jsonData))
-	if _, err := m.debugLogFile.WriteString(logLine); err != nil {
-		log.Printf("Error writing to debug log: %s\n", err)
+	fields := map[string]string{"MESSAGE": message}
+	for name, value := range attrs {
+		fields[toJournalFieldName(name)] = value
 	}
 
-	// Also log to main log for visibility
-	log.Printf("System stats recorded - Runtime: %ds, CPU temp: %s, Disk: %s",
-		stats.RuntimeSeconds,
-		stats.CpuTemperature,
-		stats.FreeDiskSpace)
+	if err := journalexport.WriteEntry(m.journalExport, fields); err != nil {
+		log.Errorf("Error writing journal export entry: %s\n", err)
+	}
 }
 
-// logEvent writes an event to the debug log
-func (m *Monitor) logEvent(eventType, message string) {
-	timestamp := time.Now().Format(time.RFC3339)
-	logLine := fmt.Sprintf("[%s] %s - %s\n", eventType, timestamp, message)
-
-	if m.debugLogFile != nil {
-		if _, err := m.debugLogFile.WriteString(logLine); err != nil {
-			log.Printf("Error writing event to debug log: %s\n", err)
+// toJournalFieldName converts a camelCase attribute name, e.g.
+// "cpuTemperature", into the upper-cased, underscore-separated form the
+// Journal Export Format expects, e.g. "CPU_TEMPERATURE".
+func toJournalFieldName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
 		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// logEvent writes a structured event via m.eventer and mirrors it to the
+// logger for visibility on stdout/file logging. Stats events fire every
+// MonitoringInterval and are mirrored at Debug level; everything else is
+// infrequent enough to show at Info level.
+func (m *Monitor) logEvent(eventType events.Type, message string, attrs map[string]string) {
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	attrs["message"] = message
+
+	if err := m.eventer.Write(events.Event{Type: eventType, Timestamp: time.Now(), Attributes: attrs}); err != nil {
+		log.Errorf("Error writing event: %s\n", err)
 	}
 
-	log.Printf("[%s] %s", eventType, message)
+	if eventType == events.Stats {
+		log.Debugf("[%s] %s", eventType, message)
+	} else {
+		log.Infof("[%s] %s", eventType, message)
+	}
 }
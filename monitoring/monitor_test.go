@@ -1,16 +1,18 @@
 package monitoring
 
 import (
-	"os"
+	"bytes"
+	"context"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/events"
 )
 
 func TestNew(t *testing.T) {
-	// Clean up any existing debug log
-	os.Remove(DebugLogFile)
-
-	m, err := New()
+	eventer := events.NewMemoryEventer(10)
+	m, err := New(eventer)
 	if err != nil {
 		t.Fatalf("Failed to create monitor: %s", err)
 	}
@@ -20,28 +22,25 @@ func TestNew(t *testing.T) {
 		t.Error("Start time should be set")
 	}
 
-	if m.debugLogFile == nil {
-		t.Error("Debug log file should be initialized")
+	ch, err := eventer.Read(context.Background(), events.ReadOptions{Type: events.Startup})
+	if err != nil {
+		t.Fatalf("Read failed: %s", err)
 	}
-
-	// Verify the debug log file was created
-	if _, err := os.Stat(DebugLogFile); os.IsNotExist(err) {
-		t.Error("Debug log file should be created")
+	var got []events.Event
+	for e := range ch {
+		got = append(got, e)
+	}
+	if len(got) != 1 {
+		t.Error("Expected a STARTUP event to be recorded")
 	}
-
-	// Clean up
-	os.Remove(DebugLogFile)
 }
 
 func TestGetRuntimeSeconds(t *testing.T) {
-	os.Remove(DebugLogFile)
-
-	m, err := New()
+	m, err := New(events.NewMemoryEventer(10))
 	if err != nil {
 		t.Fatalf("Failed to create monitor: %s", err)
 	}
 	defer m.Close()
-	defer os.Remove(DebugLogFile)
 
 	// Sleep for a bit to accumulate runtime
 	time.Sleep(100 * time.Millisecond)
@@ -53,14 +52,11 @@ func TestGetRuntimeSeconds(t *testing.T) {
 }
 
 func TestPeriodicCheckIntervalRespected(t *testing.T) {
-	os.Remove(DebugLogFile)
-
-	m, err := New()
+	m, err := New(events.NewMemoryEventer(10))
 	if err != nil {
 		t.Fatalf("Failed to create monitor: %s", err)
 	}
 	defer m.Close()
-	defer os.Remove(DebugLogFile)
 
 	// First check should not log (interval not elapsed)
 	initialLogTime := m.lastLogTime
@@ -73,14 +69,11 @@ func TestPeriodicCheckIntervalRespected(t *testing.T) {
 }
 
 func TestDailyRuntimeTracking(t *testing.T) {
-	os.Remove(DebugLogFile)
-
-	m, err := New()
+	m, err := New(events.NewMemoryEventer(10))
 	if err != nil {
 		t.Fatalf("Failed to create monitor: %s", err)
 	}
 	defer m.Close()
-	defer os.Remove(DebugLogFile)
 
 	// Simulate some runtime
 	m.startTime = time.Now().Add(-1 * time.Hour)
@@ -95,14 +88,11 @@ func TestDailyRuntimeTracking(t *testing.T) {
 }
 
 func TestCollectStats(t *testing.T) {
-	os.Remove(DebugLogFile)
-
-	m, err := New()
+	m, err := New(events.NewMemoryEventer(10))
 	if err != nil {
 		t.Fatalf("Failed to create monitor: %s", err)
 	}
 	defer m.Close()
-	defer os.Remove(DebugLogFile)
 
 	stats := m.collectStats()
 
@@ -117,3 +107,35 @@ func TestCollectStats(t *testing.T) {
 	// Note: Other fields may be empty or contain error messages if commands fail
 	// This is expected in a test environment
 }
+
+func TestLogStatsExportsToJournal(t *testing.T) {
+	var buf bytes.Buffer
+	m, err := New(events.NewMemoryEventer(10), WithJournalExport(&buf))
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %s", err)
+	}
+	defer m.Close()
+
+	m.logStats(m.collectStats())
+
+	out := buf.String()
+	if !strings.Contains(out, "MESSAGE=") {
+		t.Errorf("Expected journal export output to contain a MESSAGE field, got: %s", out)
+	}
+	if !strings.HasSuffix(out, "\n\n") {
+		t.Errorf("Expected journal export entry to be terminated with a blank line, got: %s", out)
+	}
+}
+
+func TestToJournalFieldName(t *testing.T) {
+	cases := map[string]string{
+		"runtimeSeconds": "RUNTIME_SECONDS",
+		"cpuTemperature": "CPU_TEMPERATURE",
+		"systemClock":    "SYSTEM_CLOCK",
+	}
+	for in, want := range cases {
+		if got := toJournalFieldName(in); got != want {
+			t.Errorf("toJournalFieldName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
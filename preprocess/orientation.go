@@ -0,0 +1,86 @@
+package preprocess
+
+import (
+	"image"
+	"image/draw"
+)
+
+// applyOrientation returns img transformed according to the EXIF
+// Orientation tag value (1-8, per the TIFF/EXIF spec); the table below
+// matches the one used by libjpeg/ImageMagick. Values outside 2-8 (i.e. 1,
+// "normal", or anything unrecognized) are returned unchanged.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return flipHorizontal(toNRGBA(img))
+	case 3:
+		return rotate180(toNRGBA(img))
+	case 4:
+		return flipVertical(toNRGBA(img))
+	case 5:
+		return rotate270CW(flipHorizontal(toNRGBA(img)))
+	case 6:
+		return rotate90CW(toNRGBA(img))
+	case 7:
+		return rotate90CW(flipHorizontal(toNRGBA(img)))
+	case 8:
+		return rotate270CW(toNRGBA(img))
+	default:
+		return img
+	}
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok {
+		return nrgba
+	}
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+func flipHorizontal(src *image.NRGBA) *image.NRGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.SetNRGBA(w-1-x, y, src.NRGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+func flipVertical(src *image.NRGBA) *image.NRGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.SetNRGBA(x, h-1-y, src.NRGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// rotate90CW rotates src 90 degrees clockwise, swapping width and height.
+func rotate90CW(src *image.NRGBA) *image.NRGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, h, w))
+	for nx := 0; nx < h; nx++ {
+		for ny := 0; ny < w; ny++ {
+			dst.SetNRGBA(nx, ny, src.NRGBAAt(b.Min.X+ny, b.Min.Y+h-1-nx))
+		}
+	}
+	return dst
+}
+
+func rotate180(src *image.NRGBA) *image.NRGBA {
+	return rotate90CW(rotate90CW(src))
+}
+
+func rotate270CW(src *image.NRGBA) *image.NRGBA {
+	return rotate90CW(rotate90CW(rotate90CW(src)))
+}
@@ -0,0 +1,75 @@
+package preprocess
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// asymmetricImage builds a 3x2 NRGBA where every pixel is distinguishable,
+// so rotation/flip results can be checked pixel-by-pixel.
+func asymmetricImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(0, 0, color.NRGBA{1, 0, 0, 255})
+	img.Set(1, 0, color.NRGBA{2, 0, 0, 255})
+	img.Set(2, 0, color.NRGBA{3, 0, 0, 255})
+	img.Set(0, 1, color.NRGBA{4, 0, 0, 255})
+	img.Set(1, 1, color.NRGBA{5, 0, 0, 255})
+	img.Set(2, 1, color.NRGBA{6, 0, 0, 255})
+	return img
+}
+
+func pixelR(img *image.NRGBA, x, y int) uint8 {
+	return img.NRGBAAt(x, y).R
+}
+
+func TestApplyOrientationNormalIsUnchanged(t *testing.T) {
+	src := asymmetricImage()
+	got := applyOrientation(src, 1)
+	if got != image.Image(src) {
+		t.Errorf("orientation 1 should return the image unchanged")
+	}
+}
+
+func TestApplyOrientationFlipHorizontal(t *testing.T) {
+	got := toNRGBA(applyOrientation(asymmetricImage(), 2))
+	if pixelR(got, 0, 0) != 3 || pixelR(got, 2, 0) != 1 {
+		t.Errorf("orientation 2 did not mirror horizontally: got[0,0]=%d got[2,0]=%d", pixelR(got, 0, 0), pixelR(got, 2, 0))
+	}
+}
+
+func TestApplyOrientationRotate180(t *testing.T) {
+	got := toNRGBA(applyOrientation(asymmetricImage(), 3))
+	if pixelR(got, 0, 0) != 6 || pixelR(got, 2, 1) != 1 {
+		t.Errorf("orientation 3 did not rotate 180: got[0,0]=%d got[2,1]=%d", pixelR(got, 0, 0), pixelR(got, 2, 1))
+	}
+}
+
+func TestApplyOrientationFlipVertical(t *testing.T) {
+	got := toNRGBA(applyOrientation(asymmetricImage(), 4))
+	if pixelR(got, 0, 0) != 4 || pixelR(got, 0, 1) != 1 {
+		t.Errorf("orientation 4 did not mirror vertically: got[0,0]=%d got[0,1]=%d", pixelR(got, 0, 0), pixelR(got, 0, 1))
+	}
+}
+
+func TestApplyOrientationRotate90CW(t *testing.T) {
+	got := toNRGBA(applyOrientation(asymmetricImage(), 6))
+	b := got.Bounds()
+	if b.Dx() != 2 || b.Dy() != 3 {
+		t.Fatalf("orientation 6 should swap dimensions to 2x3, got %dx%d", b.Dx(), b.Dy())
+	}
+	if pixelR(got, 0, 0) != 4 || pixelR(got, 1, 2) != 3 {
+		t.Errorf("orientation 6 did not rotate 90 CW: got[0,0]=%d got[1,2]=%d", pixelR(got, 0, 0), pixelR(got, 1, 2))
+	}
+}
+
+func TestApplyOrientationRotate270CW(t *testing.T) {
+	got := toNRGBA(applyOrientation(asymmetricImage(), 8))
+	b := got.Bounds()
+	if b.Dx() != 2 || b.Dy() != 3 {
+		t.Fatalf("orientation 8 should swap dimensions to 2x3, got %dx%d", b.Dx(), b.Dy())
+	}
+	if pixelR(got, 0, 0) != 3 || pixelR(got, 1, 2) != 4 {
+		t.Errorf("orientation 8 did not rotate 270 CW: got[0,0]=%d got[1,2]=%d", pixelR(got, 0, 0), pixelR(got, 1, 2))
+	}
+}
@@ -0,0 +1,200 @@
+package preprocess
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+const (
+	lanczosSupport  = 3.0
+	bilinearSupport = 1.0
+)
+
+// Resize scales img to exactly dstW x dstH using the given resampler.
+func Resize(img image.Image, dstW, dstH int, resampler Resampler) image.Image {
+	src := toNRGBA(img)
+	if dstW <= 0 || dstH <= 0 {
+		return src
+	}
+	if resampler == NearestNeighbor {
+		return resizeNearestNeighbor(src, dstW, dstH)
+	}
+
+	kernel, support := bilinearKernel, bilinearSupport
+	if resampler == Lanczos {
+		kernel, support = lanczosKernel, lanczosSupport
+	}
+	return resizeSeparable(src, dstW, dstH, kernel, support)
+}
+
+// resizeToFit scales img down so its longest edge is at most maxDimension,
+// preserving aspect ratio. Images already within bounds (or a non-positive
+// maxDimension) are returned unchanged.
+func resizeToFit(img image.Image, maxDimension int, resampler Resampler) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if maxDimension <= 0 || (w <= maxDimension && h <= maxDimension) {
+		return img
+	}
+
+	scale := float64(maxDimension) / float64(w)
+	if h > w {
+		scale = float64(maxDimension) / float64(h)
+	}
+	dstW := int(math.Round(float64(w) * scale))
+	dstH := int(math.Round(float64(h) * scale))
+	if dstW < 1 {
+		dstW = 1
+	}
+	if dstH < 1 {
+		dstH = 1
+	}
+	return Resize(img, dstW, dstH, resampler)
+}
+
+// lanczosKernel is the windowed-sinc Lanczos-3 kernel.
+func lanczosKernel(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	if x < -lanczosSupport || x > lanczosSupport {
+		return 0
+	}
+	piX := math.Pi * x
+	return lanczosSupport * math.Sin(piX) * math.Sin(piX/lanczosSupport) / (piX * piX)
+}
+
+func bilinearKernel(x float64) float64 {
+	if x < 0 {
+		x = -x
+	}
+	if x < 1 {
+		return 1 - x
+	}
+	return 0
+}
+
+// resampleWeights holds the contributing source-pixel range and their
+// normalized weights for a single destination pixel along one axis.
+type resampleWeights struct {
+	start   int
+	weights []float64
+}
+
+// planResampling computes, for each of dstN destination indices, the
+// contributing range of source indices in [0, srcN) and their normalized
+// kernel weights. When downscaling, support is widened by the scale factor
+// so the kernel still covers enough source samples to avoid aliasing.
+func planResampling(srcN, dstN int, kernel func(float64) float64, support float64) []resampleWeights {
+	scale := float64(srcN) / float64(dstN)
+	filterScale := scale
+	if filterScale < 1.0 {
+		filterScale = 1.0
+	}
+	filterSupport := support * filterScale
+
+	plans := make([]resampleWeights, dstN)
+	for i := 0; i < dstN; i++ {
+		center := (float64(i)+0.5)*scale - 0.5
+
+		start := int(math.Floor(center - filterSupport))
+		end := int(math.Ceil(center + filterSupport))
+		if start < 0 {
+			start = 0
+		}
+		if end > srcN-1 {
+			end = srcN - 1
+		}
+
+		weights := make([]float64, 0, end-start+1)
+		var sum float64
+		for s := start; s <= end; s++ {
+			w := kernel((float64(s) - center) / filterScale)
+			weights = append(weights, w)
+			sum += w
+		}
+		if sum != 0 {
+			for j := range weights {
+				weights[j] /= sum
+			}
+		}
+		plans[i] = resampleWeights{start: start, weights: weights}
+	}
+	return plans
+}
+
+// resizeSeparable performs a two-pass resize: a horizontal pass into an
+// intermediate float64 RGBA buffer, then a vertical pass into the final
+// image. Separating the axes keeps the per-pixel cost linear in the kernel
+// support rather than quadratic.
+func resizeSeparable(src *image.NRGBA, dstW, dstH int, kernel func(float64) float64, support float64) *image.NRGBA {
+	b := src.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+
+	hPlan := planResampling(srcW, dstW, kernel, support)
+	vPlan := planResampling(srcH, dstH, kernel, support)
+
+	// Horizontal pass: srcH rows x dstW columns, 4 float64 channels each.
+	intermediate := make([][4]float64, srcH*dstW)
+	for y := 0; y < srcH; y++ {
+		for x := 0; x < dstW; x++ {
+			plan := hPlan[x]
+			var r, g, a2, bl float64
+			for j, w := range plan.weights {
+				px := src.NRGBAAt(b.Min.X+plan.start+j, b.Min.Y+y)
+				r += float64(px.R) * w
+				g += float64(px.G) * w
+				bl += float64(px.B) * w
+				a2 += float64(px.A) * w
+			}
+			intermediate[y*dstW+x] = [4]float64{r, g, bl, a2}
+		}
+	}
+
+	// Vertical pass.
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		plan := vPlan[y]
+		for x := 0; x < dstW; x++ {
+			var r, g, bl, a2 float64
+			for j, w := range plan.weights {
+				px := intermediate[(plan.start+j)*dstW+x]
+				r += px[0] * w
+				g += px[1] * w
+				bl += px[2] * w
+				a2 += px[3] * w
+			}
+			dst.SetNRGBA(x, y, clampToNRGBA(r, g, bl, a2))
+		}
+	}
+	return dst
+}
+
+func resizeNearestNeighbor(src *image.NRGBA, dstW, dstH int) *image.NRGBA {
+	b := src.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		sy := y * srcH / dstH
+		for x := 0; x < dstW; x++ {
+			sx := x * srcW / dstW
+			dst.SetNRGBA(x, y, src.NRGBAAt(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+func clampToNRGBA(r, g, bl, a float64) color.NRGBA {
+	return color.NRGBA{clampByte(r), clampByte(g), clampByte(bl), clampByte(a)}
+}
+
+func clampByte(v float64) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,76 @@
+package preprocess
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func uniformImage(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestResizeToFitWithinBoundsUnchanged(t *testing.T) {
+	img := uniformImage(100, 50, color.NRGBA{10, 20, 30, 255})
+	got := resizeToFit(img, 200, Lanczos)
+	if got != image.Image(img) {
+		t.Errorf("image within bounds should be returned unchanged")
+	}
+}
+
+func TestResizeToFitScalesLongEdge(t *testing.T) {
+	img := uniformImage(200, 100, color.NRGBA{10, 20, 30, 255})
+	got := resizeToFit(img, 50, Lanczos)
+	b := got.Bounds()
+	if b.Dx() != 50 || b.Dy() != 25 {
+		t.Errorf("expected 50x25 to preserve aspect ratio, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestResizeUniformColorStaysUniform(t *testing.T) {
+	c := color.NRGBA{120, 60, 200, 255}
+	img := uniformImage(40, 40, c)
+
+	for _, resampler := range []Resampler{NearestNeighbor, Bilinear, Lanczos} {
+		out := toNRGBA(Resize(img, 10, 10, resampler))
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				got := out.NRGBAAt(x, y)
+				if got != c {
+					t.Errorf("resampler %v: pixel (%d,%d) = %v, expected uniform %v", resampler, x, y, got, c)
+				}
+			}
+		}
+	}
+}
+
+func TestResizeUpscalePreservesDimensions(t *testing.T) {
+	img := uniformImage(10, 10, color.NRGBA{1, 2, 3, 255})
+	out := Resize(img, 30, 20, Lanczos)
+	b := out.Bounds()
+	if b.Dx() != 30 || b.Dy() != 20 {
+		t.Errorf("expected 30x20, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestPlanResamplingNormalizesWeights(t *testing.T) {
+	plans := planResampling(20, 10, lanczosKernel, lanczosSupport)
+	if len(plans) != 10 {
+		t.Fatalf("expected 10 plans, got %d", len(plans))
+	}
+	for i, plan := range plans {
+		var sum float64
+		for _, w := range plan.weights {
+			sum += w
+		}
+		if sum < 0.99 || sum > 1.01 {
+			t.Errorf("plan %d weights sum to %v, expected ~1.0", i, sum)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+)
+
+// GetActor serves the ActivityPub actor document describing this device.
+func GetActor(w http.ResponseWriter, _ *http.Request) {
+	settings, err := conf.LoadConfiguration()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	key, err := parsePrivateKey(settings.PublishPrivateKeyPEM)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	publicKeyPEM, err := publicKeyPEMFromPrivate(key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	actorID := ActorID(*settings)
+	actor := Actor{
+		Context:           []string{"https://www.w3.org/ns/activitystreams", "https://w3id.org/security/v1"},
+		ID:                actorID,
+		Type:              "Service",
+		PreferredUsername: settings.PublishActorHandle,
+		Name:              settings.PublishActorHandle,
+		Inbox:             actorID + "/inbox",
+		Outbox:            actorID + "/outbox",
+		Followers:         actorID + "/followers",
+		PublicKey: PublicKey{
+			ID:           actorID + "#main-key",
+			Owner:        actorID,
+			PublicKeyPem: publicKeyPEM,
+		},
+	}
+
+	w.Header().Set(conf.HeaderContentType, "application/activity+json")
+	json.NewEncoder(w).Encode(actor)
+}
+
+// GetOutbox serves a minimal ActivityPub OrderedCollection. Posts are
+// delivered directly to followers' inboxes as they're captured (see
+// Publish), so the outbox exists mainly to satisfy actor discovery.
+func GetOutbox(w http.ResponseWriter, _ *http.Request) {
+	settings, err := conf.LoadConfiguration()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	actorID := ActorID(*settings)
+	resp := map[string]interface{}{
+		"@context":     activityStreamsContext,
+		"id":           actorID + "/outbox",
+		"type":         "OrderedCollection",
+		"totalItems":   0,
+		"orderedItems": []interface{}{},
+	}
+	w.Header().Set(conf.HeaderContentType, "application/activity+json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetWebfinger serves the minimal WebFinger response Mastodon/GoToSocial use
+// to resolve @handle@domain to the actor document.
+func GetWebfinger(w http.ResponseWriter, r *http.Request) {
+	settings, err := conf.LoadConfiguration()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resource := r.URL.Query().Get("resource")
+	expected := fmt.Sprintf("acct:%s@%s", settings.PublishActorHandle, settings.PublishDomain)
+	if !strings.EqualFold(resource, expected) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"subject": expected,
+		"links": []map[string]string{{
+			"rel":  "self",
+			"type": "application/activity+json",
+			"href": ActorID(*settings),
+		}},
+	}
+	w.Header().Set(conf.HeaderContentType, "application/jrd+json")
+	json.NewEncoder(w).Encode(resp)
+}
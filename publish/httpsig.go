@@ -0,0 +1,36 @@
+package publish
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// signRequest signs req using the HTTP Signatures draft (RSA-SHA256) over
+// the (request-target), host, and date headers, as required by Mastodon and
+// GoToSocial for inbox delivery.
+func signRequest(req *http.Request, keyID string, privateKey *rsa.PrivateKey) error {
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("Host", req.URL.Host)
+
+	requestTarget := fmt.Sprintf("%s %s", strings.ToLower(req.Method), req.URL.RequestURI())
+	signingString := fmt.Sprintf("(request-target): %s\nhost: %s\ndate: %s",
+		requestTarget, req.Header.Get("Host"), req.Header.Get("Date"))
+
+	hashed := sha256.Sum256([]byte(signingString))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Signature", fmt.Sprintf(
+		`keyId="%s",algorithm="rsa-sha256",headers="(request-target) host date",signature="%s"`,
+		keyID, base64.StdEncoding.EncodeToString(signature)))
+	return nil
+}
@@ -0,0 +1,145 @@
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+)
+
+// PostInbox accepts Follow/Undo activities from other instances. Follow
+// requests from hosts not present in PublishFollowedInstanceAllowlist (when
+// set) are rejected with 403; otherwise the sender's inbox is resolved and
+// recorded so Publish can deliver future posts to it.
+func PostInbox(w http.ResponseWriter, r *http.Request) {
+	var activity Activity
+	if err := json.NewDecoder(r.Body).Decode(&activity); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	settings, err := conf.LoadConfiguration()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch activity.Type {
+	case "Follow":
+		handleFollow(w, *settings, activity)
+	case "Undo":
+		// Minimal handling: acknowledge receipt. Actually removing the
+		// follower's inbox would require re-resolving the nested Follow
+		// object, which isn't worth the complexity for a device that mostly
+		// just posts photos.
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		log.Printf("Ignoring unsupported inbox activity type: %s\n", activity.Type)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func handleFollow(w http.ResponseWriter, settings conf.Settings, follow Activity) {
+	if follow.Actor == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !isAllowedInstance(settings, follow.Actor) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	followerInbox, err := resolveInbox(follow.Actor)
+	if err != nil {
+		log.Printf("Failed to resolve follower actor %s: %s\n", follow.Actor, err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	addFollowerInbox(&settings, followerInbox)
+	if _, err := conf.WriteConfiguration(settings); err != nil {
+		log.Printf("Failed to persist new follower: %s\n", err.Error())
+	}
+	if err := sendAccept(settings, follow, followerInbox); err != nil {
+		log.Printf("Failed to send Accept to %s: %s\n", followerInbox, err.Error())
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func isAllowedInstance(settings conf.Settings, actorID string) bool {
+	if settings.PublishFollowedInstanceAllowlist == "" {
+		return true
+	}
+	parsed, err := url.Parse(actorID)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range strings.Split(settings.PublishFollowedInstanceAllowlist, ",") {
+		if strings.EqualFold(strings.TrimSpace(allowed), parsed.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveInbox fetches a remote actor document to find the inbox URL to
+// deliver to, since Follow activities only carry the actor ID.
+func resolveInbox(actorID string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, actorID, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/activity+json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var actor Actor
+	if err := json.NewDecoder(resp.Body).Decode(&actor); err != nil {
+		return "", err
+	}
+	if actor.Inbox == "" {
+		return "", fmt.Errorf("actor document for %s has no inbox", actorID)
+	}
+	return actor.Inbox, nil
+}
+
+func addFollowerInbox(settings *conf.Settings, inbox string) {
+	for _, existing := range followerInboxes(*settings) {
+		if existing == inbox {
+			return
+		}
+	}
+	if settings.PublishFollowerInboxes == "" {
+		settings.PublishFollowerInboxes = inbox
+	} else {
+		settings.PublishFollowerInboxes += "," + inbox
+	}
+}
+
+func sendAccept(settings conf.Settings, follow Activity, followerInbox string) error {
+	privateKey, err := parsePrivateKey(settings.PublishPrivateKeyPEM)
+	if err != nil {
+		return err
+	}
+	actorID := ActorID(settings)
+	accept := Activity{
+		Context: activityStreamsContext,
+		Type:    "Accept",
+		Actor:   actorID,
+		Object:  follow,
+	}
+	body, err := json.Marshal(accept)
+	if err != nil {
+		return err
+	}
+	return deliver(followerInbox, actorID, privateKey, body)
+}
@@ -0,0 +1,63 @@
+package publish
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+)
+
+const rsaKeyBits = 2048
+
+var errNoPEMData = errors.New("no PEM data found in private key")
+
+// GenerateKeyPair creates a new RSA keypair, PEM-encoding both halves.
+func GenerateKeyPair() (privatePEM, publicPEM string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return "", "", err
+	}
+
+	privBlock := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	pubPEM, err := publicKeyPEMFromPrivate(key)
+	if err != nil {
+		return "", "", err
+	}
+	return string(privBlock), pubPEM, nil
+}
+
+// EnsureKeyPair generates and persists an RSA keypair into settings if
+// PublishPrivateKeyPEM isn't already set, so the actor's public key stays
+// stable across restarts.
+func EnsureKeyPair(settings *conf.Settings) error {
+	if settings.PublishPrivateKeyPEM != "" {
+		return nil
+	}
+	privatePEM, _, err := GenerateKeyPair()
+	if err != nil {
+		return err
+	}
+	settings.PublishPrivateKeyPEM = privatePEM
+	_, err = conf.WriteConfiguration(*settings)
+	return err
+}
+
+func parsePrivateKey(privatePEM string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errNoPEMData
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+func publicKeyPEMFromPrivate(key *rsa.PrivateKey) (string, error) {
+	pubBytes, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes})), nil
+}
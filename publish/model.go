@@ -0,0 +1,56 @@
+// Package publish hosts a minimal ActivityPub actor so a timelapse device
+// can be followed from Mastodon/GoToSocial: each newly-captured photo is
+// posted as a Create/Note activity, signed with HTTP Signatures and
+// delivered directly to followers' inboxes.
+package publish
+
+// Actor is the minimal ActivityPub actor document served at /actor.
+type Actor struct {
+	Context           []string  `json:"@context"`
+	ID                string    `json:"id"`
+	Type              string    `json:"type"`
+	PreferredUsername string    `json:"preferredUsername"`
+	Name              string    `json:"name"`
+	Inbox             string    `json:"inbox"`
+	Outbox            string    `json:"outbox"`
+	Followers         string    `json:"followers"`
+	PublicKey         PublicKey `json:"publicKey"`
+}
+
+// PublicKey is the security-vocabulary public key block embedded in Actor,
+// used by remote servers to verify our signed deliveries.
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// Activity is a minimal ActivityStreams envelope covering the Create,
+// Follow, Undo and Accept activities this package sends or receives.
+type Activity struct {
+	Context   interface{} `json:"@context,omitempty"`
+	ID        string      `json:"id,omitempty"`
+	Type      string      `json:"type"`
+	Actor     string      `json:"actor,omitempty"`
+	Object    interface{} `json:"object,omitempty"`
+	Published string      `json:"published,omitempty"`
+	To        []string    `json:"to,omitempty"`
+}
+
+// Note is the object embedded in an outbound Create activity.
+type Note struct {
+	ID           string       `json:"id"`
+	Type         string       `json:"type"`
+	AttributedTo string       `json:"attributedTo"`
+	Content      string       `json:"content"`
+	Published    string       `json:"published"`
+	To           []string     `json:"to"`
+	Attachment   []Attachment `json:"attachment,omitempty"`
+}
+
+// Attachment describes the photo attached to a Note.
+type Attachment struct {
+	Type      string `json:"type"`
+	MediaType string `json:"mediaType"`
+	URL       string `json:"url"`
+}
@@ -0,0 +1,144 @@
+package publish
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+)
+
+const (
+	activityStreamsContext = "https://www.w3.org/ns/activitystreams"
+	maxDeliveryAttempts    = 3
+
+	// httpTimeout bounds every outbound request this package makes
+	// (delivering to a follower inbox, resolving a follower's actor
+	// document). Without it, a follower whose server accepts the connection
+	// and never responds would hang the caller indefinitely; deliver is
+	// called from the scheduled capture loop, and resolveInbox from the
+	// PostInbox HTTP handler, so either running away would stall timelapse
+	// captures or a request goroutine.
+	httpTimeout = 10 * time.Second
+)
+
+// httpClient is shared by deliver and resolveInbox; see httpTimeout.
+var httpClient = &http.Client{Timeout: httpTimeout}
+
+// ActorID returns the fully-qualified ActivityPub actor ID for the
+// configured domain.
+func ActorID(settings conf.Settings) string {
+	return fmt.Sprintf("https://%s/actor", settings.PublishDomain)
+}
+
+// Publish posts photoURL as a Create/Note activity (optionally using
+// summary, e.g. from the detection package, as the post body) to every
+// known follower inbox, signing each delivery with HTTP Signatures.
+func Publish(settings conf.Settings, photoURL, summary string) error {
+	if !settings.PublishEnabled {
+		return nil
+	}
+	privateKey, err := parsePrivateKey(settings.PublishPrivateKeyPEM)
+	if err != nil {
+		return fmt.Errorf("failed to load publishing key: %v", err)
+	}
+
+	actorID := ActorID(settings)
+	content := summary
+	if !settings.PublishAttachDetectionSummary || content == "" {
+		content = "New timelapse frame captured."
+	}
+	audience := visibilityAudience(settings.PublishVisibility, actorID)
+
+	note := Note{
+		ID:           fmt.Sprintf("%s/notes/%d", actorID, time.Now().UnixNano()),
+		Type:         "Note",
+		AttributedTo: actorID,
+		Content:      content,
+		Published:    time.Now().UTC().Format(time.RFC3339),
+		To:           []string{audience},
+		Attachment: []Attachment{{
+			Type:      "Document",
+			MediaType: "image/jpeg",
+			URL:       photoURL,
+		}},
+	}
+	activity := Activity{
+		Context:   activityStreamsContext,
+		ID:        note.ID + "/activity",
+		Type:      "Create",
+		Actor:     actorID,
+		Object:    note,
+		Published: note.Published,
+		To:        note.To,
+	}
+
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, inbox := range followerInboxes(settings) {
+		if err := deliverWithRetry(inbox, actorID, privateKey, body); err != nil {
+			log.Printf("Failed to deliver post to %s: %s\n", inbox, err.Error())
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// deliverWithRetry retries a failed delivery with exponential backoff, since
+// a follower's instance being briefly unreachable shouldn't drop the post.
+func deliverWithRetry(inbox, actorID string, key *rsa.PrivateKey, body []byte) error {
+	var err error
+	for attempt := 0; attempt < maxDeliveryAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<attempt) * time.Second)
+		}
+		if err = deliver(inbox, actorID, key, body); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func deliver(inbox, actorID string, key *rsa.PrivateKey, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, inbox, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/activity+json")
+	if err := signRequest(req, actorID+"#main-key", key); err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("inbox %s responded with status %d", inbox, resp.StatusCode)
+	}
+	return nil
+}
+
+func visibilityAudience(visibility, actorID string) string {
+	if visibility == "unlisted" {
+		return actorID + "/followers"
+	}
+	return "https://www.w3.org/ns/activitystreams#Public"
+}
+
+func followerInboxes(settings conf.Settings) []string {
+	if settings.PublishFollowerInboxes == "" {
+		return nil
+	}
+	return strings.Split(settings.PublishFollowerInboxes, ",")
+}
@@ -0,0 +1,47 @@
+package publish
+
+import (
+	"testing"
+
+	"github.com/facebookgo/ensure"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+)
+
+func TestVisibilityAudience(t *testing.T) {
+	actorID := "https://timelapse.example.com/actor"
+	ensure.DeepEqual(t, "https://www.w3.org/ns/activitystreams#Public", visibilityAudience("public", actorID))
+	ensure.DeepEqual(t, actorID+"/followers", visibilityAudience("unlisted", actorID))
+}
+
+func TestFollowerInboxes(t *testing.T) {
+	ensure.DeepEqual(t, 0, len(followerInboxes(conf.Settings{})))
+
+	settings := conf.Settings{PublishFollowerInboxes: "https://a.example/inbox,https://b.example/inbox"}
+	ensure.DeepEqual(t, []string{"https://a.example/inbox", "https://b.example/inbox"}, followerInboxes(settings))
+}
+
+func TestAddFollowerInboxDedupes(t *testing.T) {
+	settings := conf.Settings{}
+	addFollowerInbox(&settings, "https://a.example/inbox")
+	ensure.DeepEqual(t, "https://a.example/inbox", settings.PublishFollowerInboxes)
+
+	addFollowerInbox(&settings, "https://b.example/inbox")
+	ensure.DeepEqual(t, "https://a.example/inbox,https://b.example/inbox", settings.PublishFollowerInboxes)
+
+	addFollowerInbox(&settings, "https://a.example/inbox")
+	ensure.DeepEqual(t, "https://a.example/inbox,https://b.example/inbox", settings.PublishFollowerInboxes)
+}
+
+func TestIsAllowedInstance(t *testing.T) {
+	// Empty allowlist permits any instance.
+	ensure.True(t, isAllowedInstance(conf.Settings{}, "https://mastodon.social/users/someone"))
+
+	settings := conf.Settings{PublishFollowedInstanceAllowlist: "mastodon.social, example.social"}
+	ensure.True(t, isAllowedInstance(settings, "https://mastodon.social/users/someone"))
+	ensure.False(t, isAllowedInstance(settings, "https://untrusted.example/users/someone"))
+}
+
+func TestActorID(t *testing.T) {
+	settings := conf.Settings{PublishDomain: "timelapse.example.com"}
+	ensure.DeepEqual(t, "https://timelapse.example.com/actor", ActorID(settings))
+}
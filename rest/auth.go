@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Capability identifies an action a Route requires the caller's Identity to
+// be authorized for.
+type Capability string
+
+const (
+	CapCapture     Capability = "capture"
+	CapConfigWrite Capability = "config:write"
+	CapArchiveRead Capability = "archive:read"
+	CapAdminExec   Capability = "admin:exec"
+)
+
+// Identity is the caller resolved by an Authenticator, carrying the
+// capabilities it's allowed to exercise.
+type Identity struct {
+	Name         string
+	Capabilities map[Capability]bool
+}
+
+// Can reports whether id is authorized for cap. A zero Identity (no
+// capabilities set) is authorized for nothing.
+func (id Identity) Can(cap Capability) bool {
+	return id.Capabilities[cap]
+}
+
+// allCapabilities is granted to every authenticated identity; this repo has
+// no multi-user/role model yet, so "authenticated" and "authorized for
+// everything" are currently the same thing.
+var allCapabilities = map[Capability]bool{
+	CapCapture:     true,
+	CapConfigWrite: true,
+	CapArchiveRead: true,
+	CapAdminExec:   true,
+}
+
+// Authenticator resolves the caller of an incoming request to an Identity,
+// or reports ok=false if the request carries no valid credentials.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Identity, bool)
+}
+
+// NoopAuth authenticates every request as a single anonymous identity
+// authorized for everything. This is the default API.NewAPI uses, so the
+// server behaves the way it always has unless a real Authenticator is
+// configured.
+type NoopAuth struct{}
+
+func (NoopAuth) Authenticate(_ *http.Request) (Identity, bool) {
+	return Identity{Name: "anonymous", Capabilities: allCapabilities}, true
+}
+
+// TokenAuth requires an "Authorization: Bearer <token>" header matching
+// Token, e.g. the value persisted via conf.StoreAPIToken.
+type TokenAuth struct {
+	Token string
+}
+
+func (a TokenAuth) Authenticate(r *http.Request) (Identity, bool) {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return Identity{}, false
+	}
+	presented := strings.TrimPrefix(header, prefix)
+	if a.Token == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(a.Token)) != 1 {
+		return Identity{}, false
+	}
+	return Identity{Name: "token", Capabilities: allCapabilities}, true
+}
@@ -0,0 +1,56 @@
+package rest
+
+import "net/http"
+
+// LoginRequest is the body of POST /auth/login.
+type LoginRequest struct {
+	Token string `json:"token"`
+}
+
+// LoginResponse reports whether Token authenticated successfully, letting
+// the frontend confirm credentials before storing them for later requests.
+type LoginResponse struct {
+	Valid bool `json:"valid"`
+}
+
+// MakeLoginFn checks req.Token against authenticator without requiring the
+// caller to already be authenticated, so the frontend can validate a token
+// the user just typed in.
+func MakeLoginFn(authenticator Authenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req LoginRequest
+		if err := parseJSON(r.Body, &req); err != nil {
+			writeJSON(w, http.StatusBadRequest, LoginResponse{Valid: false})
+			return
+		}
+
+		probe := r.Clone(r.Context())
+		probe.Header.Set("Authorization", "Bearer "+req.Token)
+
+		_, ok := authenticator.Authenticate(probe)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, LoginResponse{Valid: false})
+			return
+		}
+		writeJSON(w, http.StatusOK, LoginResponse{Valid: true})
+	}
+}
+
+// MeResponse describes the identity that authenticated the current request.
+type MeResponse struct {
+	Name         string   `json:"name"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// GetMe reports the Identity the auth middleware resolved for this request,
+// letting the frontend decide which controls to show.
+func GetMe(w http.ResponseWriter, r *http.Request) {
+	identity, _ := identityFromContext(r.Context())
+	var caps []string
+	for capability, granted := range identity.Capabilities {
+		if granted {
+			caps = append(caps, string(capability))
+		}
+	}
+	writeJSON(w, http.StatusOK, MeResponse{Name: identity.Name, Capabilities: caps})
+}
@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+func TestNoopAuthAlwaysAuthenticates(t *testing.T) {
+	r := httptest.NewRequest("GET", "/capture", nil)
+
+	identity, ok := NoopAuth{}.Authenticate(r)
+	ensure.True(t, ok)
+	ensure.True(t, identity.Can(CapCapture))
+	ensure.True(t, identity.Can(CapAdminExec))
+}
+
+func TestTokenAuthAcceptsMatchingBearerToken(t *testing.T) {
+	r := httptest.NewRequest("GET", "/capture", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+
+	identity, ok := TokenAuth{Token: "secret"}.Authenticate(r)
+	ensure.True(t, ok)
+	ensure.True(t, identity.Can(CapArchiveRead))
+}
+
+func TestTokenAuthRejectsWrongToken(t *testing.T) {
+	r := httptest.NewRequest("GET", "/capture", nil)
+	r.Header.Set("Authorization", "Bearer wrong")
+
+	_, ok := TokenAuth{Token: "secret"}.Authenticate(r)
+	ensure.False(t, ok)
+}
+
+func TestTokenAuthRejectsMissingHeader(t *testing.T) {
+	r := httptest.NewRequest("GET", "/capture", nil)
+
+	_, ok := TokenAuth{Token: "secret"}.Authenticate(r)
+	ensure.False(t, ok)
+}
+
+func TestTokenAuthRejectsEmptyConfiguredToken(t *testing.T) {
+	r := httptest.NewRequest("GET", "/capture", nil)
+	r.Header.Set("Authorization", "Bearer ")
+
+	_, ok := TokenAuth{Token: ""}.Authenticate(r)
+	ensure.False(t, ok)
+}
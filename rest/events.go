@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/events"
+)
+
+// MakeGetEventsFn returns a handler serving the structured event history
+// recorded to eventer (see the events package), filtered by the optional
+// since/until/type/attr.<key> query parameters.
+func MakeGetEventsFn(eventer events.Eventer) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		opts, err := parseReadOptions(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		ch, err := eventer.Read(r.Context(), opts)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+
+		matched := []events.Event{}
+		for event := range ch {
+			matched = append(matched, event)
+		}
+		writeJSON(w, http.StatusOK, matched)
+	}
+}
+
+func parseReadOptions(r *http.Request) (events.ReadOptions, error) {
+	query := r.URL.Query()
+	opts := events.ReadOptions{Type: events.Type(query.Get("type"))}
+
+	if since := query.Get("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return opts, err
+		}
+		opts.Since = parsed
+	}
+	if until := query.Get("until"); until != "" {
+		parsed, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return opts, err
+		}
+		opts.Until = parsed
+	}
+
+	for key, values := range query {
+		if !strings.HasPrefix(key, "attr.") || len(values) == 0 {
+			continue
+		}
+		if opts.Attr == nil {
+			opts.Attr = map[string]string{}
+		}
+		opts.Attr[strings.TrimPrefix(key, "attr.")] = values[0]
+	}
+
+	return opts, nil
+}
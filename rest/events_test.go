@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/events"
+)
+
+func TestMakeGetEventsFn(t *testing.T) {
+	eventer := events.NewMemoryEventer(10)
+	eventer.Write(events.Event{Type: events.Capture, Attributes: map[string]string{"photoPath": "a.jpg"}})
+	eventer.Write(events.Event{Type: events.CaptureError, Attributes: map[string]string{"error": "timeout"}})
+
+	handler := MakeGetEventsFn(eventer)
+
+	req := httptest.NewRequest("GET", "/events?type=CAPTURE_ERROR", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	ensure.DeepEqual(t, 200, w.Code)
+
+	var got []events.Event
+	ensure.Nil(t, json.Unmarshal(w.Body.Bytes(), &got))
+	ensure.DeepEqual(t, 1, len(got))
+	ensure.DeepEqual(t, events.CaptureError, got[0].Type)
+}
+
+func TestParseReadOptionsInvalidSince(t *testing.T) {
+	req := httptest.NewRequest("GET", "/events?since=not-a-time", nil)
+	_, err := parseReadOptions(req)
+	ensure.NotNil(t, err)
+}
@@ -32,11 +32,7 @@ func writeJSON(w http.ResponseWriter, status int, r interface{}) error {
 
 	w.Header().Set("content-type", "application/json")
 
-	// Allow CORS
-	// TODO Limit to dev mode
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "*")
+	// CORS headers are set by API.wrap before the handler runs; see rest/api.go.
 
 	w.WriteHeader(status)
 	io.WriteString(w, string(val))
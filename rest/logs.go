@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/logcache"
+)
+
+// defaultRecentLogLines is how many lines MakeGetRecentLogsFn returns when
+// the caller doesn't specify one.
+const defaultRecentLogLines = 50
+
+// GetLogs replies with the full contents of the on-disk log file. On a Pi
+// with a slow SD card this can be expensive for a large log file; prefer
+// MakeGetRecentLogsFn for a quick tail.
+func GetLogs(w http.ResponseWriter, _ *http.Request) {
+	serveFileContent(w, conf.LogFile)
+}
+
+// MakeGetRecentLogsFn returns a handler serving the last `lines` entries
+// (defaultRecentLogLines if unspecified) from cache, without reading the log
+// file from disk.
+func MakeGetRecentLogsFn(cache *logcache.Cache) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lines := defaultRecentLogLines
+		if raw := r.URL.Query().Get("lines"); raw != "" {
+			if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+				lines = parsed
+			}
+		}
+		writeJSON(w, http.StatusOK, cache.Lines(lines))
+	}
+}
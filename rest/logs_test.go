@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/logcache"
+)
+
+func TestMakeGetRecentLogsFn(t *testing.T) {
+	cache := logcache.EnableCaching(10, 0)
+	cache.Write([]byte("first"))
+	cache.Write([]byte("second"))
+	cache.Write([]byte("third"))
+
+	handler := MakeGetRecentLogsFn(cache)
+
+	req := httptest.NewRequest("GET", "/logs/recent?lines=2", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	ensure.DeepEqual(t, 200, w.Code)
+
+	var got []string
+	ensure.Nil(t, json.Unmarshal(w.Body.Bytes(), &got))
+	ensure.DeepEqual(t, []string{"second", "third"}, got)
+}
+
+func TestMakeGetRecentLogsFnDefaultsLines(t *testing.T) {
+	cache := logcache.EnableCaching(10, 0)
+	cache.Write([]byte("only"))
+
+	handler := MakeGetRecentLogsFn(cache)
+
+	req := httptest.NewRequest("GET", "/logs/recent", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	var got []string
+	ensure.Nil(t, json.Unmarshal(w.Body.Bytes(), &got))
+	ensure.DeepEqual(t, []string{"only"}, got)
+}
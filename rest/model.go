@@ -48,3 +48,16 @@ type Photo struct {
 type DetectionResponse struct {
 	*detection.DetectionResult
 }
+
+type ExifResponse struct {
+	DateTimeOriginal string  `json:"dateTimeOriginal,omitempty"`
+	HasGPS           bool    `json:"hasGps"`
+	GPSLatitude      float64 `json:"gpsLatitude,omitempty"`
+	GPSLongitude     float64 `json:"gpsLongitude,omitempty"`
+	Make             string  `json:"make,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	UserComment      string  `json:"userComment,omitempty"`
+	ExposureTimeSec  float64 `json:"exposureTimeSec,omitempty"`
+	FNumber          float64 `json:"fNumber,omitempty"`
+	ISOSpeedRatings  int     `json:"isoSpeedRatings,omitempty"`
+}
@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter enforces a fixed number of requests per key (e.g. client IP or
+// identity name) per rolling minute. A non-positive perMinute disables rate
+// limiting entirely.
+type rateLimiter struct {
+	mu        sync.Mutex
+	perMinute int
+	seen      map[string][]time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	return &rateLimiter{perMinute: perMinute, seen: make(map[string][]time.Time)}
+}
+
+// Allow reports whether identity may make another request right now,
+// recording the attempt if so.
+func (rl *rateLimiter) Allow(identity string) bool {
+	if rl.perMinute <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-time.Minute)
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	kept := rl.seen[identity][:0]
+	for _, t := range rl.seen[identity] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) >= rl.perMinute {
+		rl.seen[identity] = kept
+		return false
+	}
+	rl.seen[identity] = append(kept, now)
+	return true
+}
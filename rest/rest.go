@@ -1,22 +1,29 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/admin"
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf/valid"
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/detection"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/exif"
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/files"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/metrics"
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/timelapse"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/video"
 	"goji.io/pat"
 )
 
@@ -48,6 +55,7 @@ func MakeUpdateConfigurationFn(configUpdatedChan chan<- conf.Settings) func(w ht
 			writeJSON(w, 400, err.Error())
 			return
 		}
+		metrics.UpdateConfigGauges(*updatedSettings)
 		writeJSON(w, 200, updatedSettings)
 		configUpdatedChan <- *updatedSettings
 	}
@@ -69,8 +77,36 @@ func GetFile(w http.ResponseWriter, r *http.Request) {
 	serveFileContent(w, fullyQualifiedPath)
 }
 
+// GetFileExif returns the EXIF metadata embedded in the given photo as JSON.
+func GetFileExif(w http.ResponseWriter, r *http.Request) {
+	name := pat.Param(r, "fileName")
+	fullyQualifiedPath := conf.StorageFolder + "/" + name
+
+	tags, err := exif.Read(fullyQualifiedPath)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	resp := ExifResponse{
+		HasGPS:          tags.HasGPS,
+		GPSLatitude:     tags.GPSLatitude,
+		GPSLongitude:    tags.GPSLongitude,
+		Make:            tags.Make,
+		Model:           tags.Model,
+		UserComment:     tags.UserComment,
+		ExposureTimeSec: tags.ExposureTime.Seconds(),
+		FNumber:         tags.FNumber,
+		ISOSpeedRatings: tags.ISOSpeedRatings,
+	}
+	if !tags.DateTimeOriginal.IsZero() {
+		resp.DateTimeOriginal = tags.DateTimeOriginal.Format("2006-01-02T15:04:05")
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 func GetMostRecentFile(w http.ResponseWriter, _ *http.Request) {
-	f, _ := files.ListFiles(conf.StorageFolder, true)
+	f, _ := files.ListFiles(conf.StorageFolder, true, false)
 	if len(f) == 0 {
 		w.WriteHeader(http.StatusNotFound)
 		return
@@ -79,8 +115,64 @@ func GetMostRecentFile(w http.ResponseWriter, _ *http.Request) {
 	serveFileContent(w, fmt.Sprintf("%s/%s", conf.StorageFolder, mostRecentFile.Name))
 }
 
-func GetFiles(w http.ResponseWriter, _ *http.Request) {
-	f, _ := files.ListFiles(conf.StorageFolder, true)
+// GetFiles lists the files in conf.StorageFolder. Two optional query
+// parameters filter by EXIF data rather than filesystem mtime, which is
+// useful when files have been rsynced or re-touched and so no longer
+// reflect their actual capture time:
+//   - since=<RFC3339 timestamp>: only files whose EXIF DateTimeOriginal is
+//     at or after since
+//   - iso_gt=<n>: only files whose EXIF ISOSpeedRatings is greater than n
+//
+// Either parameter causes each file's ExifSummary to be parsed (see
+// files.ListFiles); with neither set, EXIF parsing is skipped entirely.
+func GetFiles(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	sinceParam := query.Get("since")
+	isoGtParam := query.Get("iso_gt")
+
+	var since time.Time
+	if sinceParam != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceParam)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid since: " + err.Error()})
+			return
+		}
+		since = parsed
+	}
+
+	var isoGt int
+	if isoGtParam != "" {
+		parsed, err := strconv.Atoi(isoGtParam)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid iso_gt: " + err.Error()})
+			return
+		}
+		isoGt = parsed
+	}
+
+	f, err := files.ListFiles(conf.StorageFolder, true, sinceParam != "" || isoGtParam != "")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if sinceParam != "" || isoGtParam != "" {
+		filtered := f[:0]
+		for _, file := range f {
+			if file.Exif == nil {
+				continue
+			}
+			if sinceParam != "" && file.Exif.CaptureTime.Before(since) {
+				continue
+			}
+			if isoGtParam != "" && file.Exif.ISO <= isoGt {
+				continue
+			}
+			filtered = append(filtered, file)
+		}
+		f = filtered
+	}
+
 	resp := ListFilesResponse{f}
 
 	b, _ := json.Marshal(resp)
@@ -90,17 +182,44 @@ func GetFiles(w http.ResponseWriter, _ *http.Request) {
 
 func Capture(w http.ResponseWriter, s *conf.Settings) {
 	log.Printf("Capturing preview picture inside of %s at resolution: %d x %d\n", conf.TempFilesFolder, s.PreviewResolutionWidth, s.PreviewResolutionHeight)
-	c, err := timelapse.NewCamera(conf.TempFilesFolder, s.PreviewResolutionWidth, s.PreviewResolutionHeight, s.RotateBy == 180, s.Quality)
+	c, err := timelapse.NewCamera(conf.TempFilesFolder, s.PreviewResolutionWidth, s.PreviewResolutionHeight, s.RotateBy == 180, s.Quality, s.CaptureBackend, s.RTSPURL, timelapse.StillTuning{
+		Contrast:       s.Contrast,
+		Saturation:     s.Saturation,
+		Sharpness:      s.Sharpness,
+		Brightness:     s.Brightness,
+		ExposureMode:   s.ExposureMode,
+		AWBMode:        s.AWBMode,
+		ISO:            s.ISO,
+		ShutterSpeedUs: s.ShutterSpeedUs,
+		EVCompensation: s.EVCompensation,
+		Denoise:        s.Denoise,
+		TextOverlay:    s.TextOverlay,
+	})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Failed to instantiate camera: %s", err.Error())
 		return
 	}
+	defer c.Close()
 
-	path, err := c.Capture()
+	captureTimeout := s.CaptureTimeoutSeconds
+	if captureTimeout <= 0 {
+		captureTimeout = conf.DefaultCaptureTimeoutSeconds
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(captureTimeout)*time.Second)
+	defer cancel()
+
+	path, err := c.Capture(ctx)
 
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, timelapse.ErrCameraBusy):
+			w.WriteHeader(http.StatusTooManyRequests)
+		case errors.Is(err, timelapse.ErrCaptureTimeout):
+			w.WriteHeader(http.StatusGatewayTimeout)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
 		fmt.Fprintf(w, "Failed to take capture: %s", err.Error())
 		return
 	}
@@ -111,6 +230,27 @@ func Capture(w http.ResponseWriter, s *conf.Settings) {
 	os.Remove(path)
 }
 
+var (
+	detectionCacheMu       sync.Mutex
+	detectionCache         *detection.CachingAnalyzer
+	detectionCacheCapacity int
+	detectionCacheHamming  int
+)
+
+// detectionCacheFor returns the shared CachingAnalyzer sized to the current
+// settings, rebuilding it (and so dropping any cached entries) whenever
+// DetectionCacheCapacity or DetectionCacheHammingThreshold changes.
+func detectionCacheFor(s conf.Settings) *detection.CachingAnalyzer {
+	detectionCacheMu.Lock()
+	defer detectionCacheMu.Unlock()
+	if detectionCache == nil || detectionCacheCapacity != s.DetectionCacheCapacity || detectionCacheHamming != s.DetectionCacheHammingThreshold {
+		detectionCache = detection.NewCachingAnalyzer(s.DetectionCacheCapacity, s.DetectionCacheHammingThreshold)
+		detectionCacheCapacity = s.DetectionCacheCapacity
+		detectionCacheHamming = s.DetectionCacheHammingThreshold
+	}
+	return detectionCache
+}
+
 // GetDetection performs object detection on the most recent photo and returns results
 func GetDetection(w http.ResponseWriter, s *conf.Settings) {
 	// Load current settings to get the latest configuration
@@ -119,7 +259,7 @@ func GetDetection(w http.ResponseWriter, s *conf.Settings) {
 		writeJSON(w, 500, map[string]string{"error": fmt.Sprintf("Failed to load configuration: %s", err.Error())})
 		return
 	}
-	
+
 	if !currentSettings.ObjectDetectionEnabled {
 		writeJSON(w, 200, DetectionResponse{&detection.DetectionResult{
 			Summary: "Object detection is disabled",
@@ -128,7 +268,7 @@ func GetDetection(w http.ResponseWriter, s *conf.Settings) {
 	}
 
 	// Get the most recent photo
-	files, err := files.ListFiles(conf.StorageFolder, true)
+	files, err := files.ListFiles(conf.StorageFolder, true, false)
 	if err != nil || len(files) == 0 {
 		writeJSON(w, 404, map[string]string{"error": "No photos found"})
 		return
@@ -139,54 +279,337 @@ func GetDetection(w http.ResponseWriter, s *conf.Settings) {
 
 	// Run object detection with current settings
 	config := &detection.DetectionConfig{
-		UseOpenCV: currentSettings.UseOpenCVDetection,
-		Timeout:   time.Duration(currentSettings.DetectionTimeout) * time.Second,
+		UseOpenCV:           currentSettings.UseOpenCVDetection,
+		Timeout:             time.Duration(currentSettings.DetectionTimeout) * time.Second,
+		ModelPath:           currentSettings.DetectionModelPath,
+		ModelConfigPath:     currentSettings.DetectionModelConfigPath,
+		ModelClassesPath:    currentSettings.DetectionModelClassesPath,
+		InputSize:           currentSettings.DetectionInputSize,
+		ConfidenceThreshold: float32(currentSettings.DetectionConfidenceThreshold),
+		NMSThreshold:        float32(currentSettings.DetectionNMSThreshold),
+	}
+	var result *detection.DetectionResult
+	if currentSettings.DetectionCacheEnabled {
+		cache := detectionCacheFor(*currentSettings)
+		hitsBefore, nearHitsBefore, missesBefore := cache.Stats()
+		result, err = cache.AnalyzePhoto(photoPath, config)
+		if err == nil {
+			hitsAfter, nearHitsAfter, missesAfter := cache.Stats()
+			switch {
+			case hitsAfter > hitsBefore:
+				metrics.RecordCacheResult("hit")
+			case nearHitsAfter > nearHitsBefore:
+				metrics.RecordCacheResult("near_hit")
+			case missesAfter > missesBefore:
+				metrics.RecordCacheResult("miss")
+			}
+		}
+	} else {
+		result, err = detection.AnalyzePhotoWithConfig(photoPath, config)
 	}
-	result, err := detection.AnalyzePhotoWithConfig(photoPath, config)
 	if err != nil {
 		writeJSON(w, 500, map[string]string{"error": fmt.Sprintf("Object detection failed: %s", err.Error())})
 		return
 	}
+	metrics.RecordDetection(result)
 
 	writeJSON(w, 200, DetectionResponse{result})
 }
 
-// GetArchiveZip Reply with ZIP file containing all timelapse pictures
+// GetMetrics serves capture, detection, and configuration telemetry in the
+// Prometheus text exposition format (see the metrics package).
+func GetMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics.Handler().ServeHTTP(w, r)
+}
+
+// archiveJob tracks a single in-flight (or recently completed) ZIP/TAR
+// build, so GetArchiveProgress can report on it by ID while GetArchiveZip/
+// GetArchiveTar stream the archive itself.
+type archiveJob struct {
+	mu       sync.RWMutex
+	progress files.Progress
+	done     bool
+}
+
+// archiveJobRetention is how long a finished job's terminal progress stays
+// queryable before it's forgotten, giving GetArchiveProgress a window to
+// observe completion even if its poll lands just after the archive finishes.
+const archiveJobRetention = 5 * time.Minute
+
+var (
+	archiveJobsMu sync.Mutex
+	archiveJobs   = map[string]*archiveJob{}
+)
+
+// newArchiveJob registers a job for the archive request r is handling,
+// keyed by its "id" query parameter if the caller supplied one (so it can
+// start polling GetArchiveProgress immediately), or a generated ID otherwise.
+func newArchiveJob(r *http.Request) (string, *archiveJob) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		id = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	job := &archiveJob{}
+
+	archiveJobsMu.Lock()
+	archiveJobs[id] = job
+	archiveJobsMu.Unlock()
+
+	return id, job
+}
+
+func (j *archiveJob) update(p files.Progress) {
+	j.mu.Lock()
+	j.progress = p
+	j.mu.Unlock()
+}
+
+func (j *archiveJob) finish(id string) {
+	j.mu.Lock()
+	j.done = true
+	j.mu.Unlock()
+
+	time.AfterFunc(archiveJobRetention, func() {
+		archiveJobsMu.Lock()
+		delete(archiveJobs, id)
+		archiveJobsMu.Unlock()
+	})
+}
+
+// GetArchiveZip Reply with ZIP file containing all timelapse pictures.
+// Progress can be followed via GetArchiveProgress using the job ID returned
+// in the HeaderArchiveJobID response header.
 func GetArchiveZip(w http.ResponseWriter, r *http.Request) {
 	strFiles, _ := requestedFilesToRelativePaths(r.URL.Query()["f"]) // TODO handle error
 
+	id, job := newArchiveJob(r)
+
 	pr, pw := io.Pipe()
 	go func() {
-		if err := files.ZipWithPipes(strFiles, pw); err != nil {
+		defer pw.Close()
+		if err := files.ZipWithPipes(r.Context(), strFiles, pw, job.update); err != nil {
 			log.Println("failed to create archive", err.Error())
 		}
-		defer pw.Close()
+		job.finish(id)
 	}()
 
 	w.Header().Add(conf.HeaderContentType, "application/zip")
 	w.Header().Set(conf.HeaderContentDisposition, "attachment; filename=archive.zip")
+	w.Header().Set(conf.HeaderArchiveJobID, id)
 
 	writePipeContent(w, pr)
 }
 
-// GetArchiveTar Reply with TAR file containing all timelapse pictures
+// GetArchiveTar Reply with TAR file containing all timelapse pictures.
+// Progress can be followed via GetArchiveProgress using the job ID returned
+// in the HeaderArchiveJobID response header.
 func GetArchiveTar(w http.ResponseWriter, r *http.Request) {
 	strFiles, _ := requestedFilesToRelativePaths(r.URL.Query()["f"]) // TODO handle error
 
+	id, job := newArchiveJob(r)
+
 	pr, pw := io.Pipe()
 	go func() {
-		if err := files.TarWithPipes(strFiles, pw); err != nil {
+		defer pw.Close()
+		if err := files.TarWithPipes(r.Context(), strFiles, pw, job.update); err != nil {
 			log.Println("failed to create archive", err.Error())
 		}
-		defer pw.Close()
+		job.finish(id)
 	}()
 
 	w.Header().Add(conf.HeaderContentType, "application/tar")
 	w.Header().Set(conf.HeaderContentDisposition, "attachment; filename=archive.tar")
+	w.Header().Set(conf.HeaderArchiveJobID, id)
 
 	writePipeContent(w, pr)
 }
 
+// GetArchiveTarGz Reply with a gzip-compressed TAR file containing all
+// timelapse pictures. The gzip compression runs in parallel, blockwise (see
+// the archivegzip package), tuned via the ArchiveCompressionLevel/
+// ArchiveGzipBlockSize/ArchiveGzipWorkers settings. Progress can be followed
+// via GetArchiveProgress using the job ID returned in the HeaderArchiveJobID
+// response header.
+func GetArchiveTarGz(w http.ResponseWriter, r *http.Request) {
+	strFiles, _ := requestedFilesToRelativePaths(r.URL.Query()["f"]) // TODO handle error
+
+	currentSettings, err := conf.LoadConfiguration()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	id, job := newArchiveJob(r)
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer pw.Close()
+		if err := files.TarGzWithPipes(r.Context(), strFiles, pw, job.update, currentSettings.ArchiveCompressionLevel, currentSettings.ArchiveGzipBlockSize, currentSettings.ArchiveGzipWorkers); err != nil {
+			log.Println("failed to create archive", err.Error())
+		}
+		job.finish(id)
+	}()
+
+	w.Header().Add(conf.HeaderContentType, "application/gzip")
+	w.Header().Set(conf.HeaderContentDisposition, "attachment; filename=archive.tar.gz")
+	w.Header().Set(conf.HeaderArchiveJobID, id)
+
+	writePipeContent(w, pr)
+}
+
+// GetArchive dispatches to GetArchiveZip, GetArchiveTar or GetArchiveTarGz
+// based on the format query parameter ("zip", "tar" or "tar.gz"/"targz"), so
+// callers don't need to know about the three separate archive routes.
+// Defaults to GetArchiveZip when format is missing or unrecognized.
+func GetArchive(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("format") {
+	case "tar":
+		GetArchiveTar(w, r)
+	case "tar.gz", "targz":
+		GetArchiveTarGz(w, r)
+	default:
+		GetArchiveZip(w, r)
+	}
+}
+
+// GetArchiveProgress streams a ZIP/TAR archive build's progress (see
+// GetArchiveZip/GetArchiveTar) as Server-Sent Events until it completes, or
+// the requesting client disconnects.
+func GetArchiveProgress(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+
+	archiveJobsMu.Lock()
+	job, ok := archiveJobs[id]
+	archiveJobsMu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown archive job id"})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(conf.HeaderContentType, "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	for {
+		job.mu.RLock()
+		progress, done := job.progress, job.done
+		job.mu.RUnlock()
+
+		payload, _ := json.Marshal(progress)
+		fmt.Fprintf(w, "data: %s\n\n", payload)
+		flusher.Flush()
+		if done {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// videoEncodeProgress tracks the most recent /archive/mp4 encode so
+// GetArchiveMp4Progress can stream it to clients via Server-Sent Events.
+var videoEncodeProgress = struct {
+	mu      sync.RWMutex
+	current float64
+	done    bool
+}{done: true}
+
+func startVideoEncodeProgress() chan<- float64 {
+	videoEncodeProgress.mu.Lock()
+	videoEncodeProgress.current = 0
+	videoEncodeProgress.done = false
+	videoEncodeProgress.mu.Unlock()
+
+	ch := make(chan float64)
+	go func() {
+		for v := range ch {
+			videoEncodeProgress.mu.Lock()
+			videoEncodeProgress.current = v
+			videoEncodeProgress.mu.Unlock()
+		}
+		videoEncodeProgress.mu.Lock()
+		videoEncodeProgress.done = true
+		videoEncodeProgress.mu.Unlock()
+	}()
+	return ch
+}
+
+// GetArchiveMp4 streams an H.264/MP4 encoding of the captured pictures,
+// assembled via the video package (a native ffmpeg binary on PATH; no
+// embedded WASM runtime is included). Progress can be followed via
+// GetArchiveMp4Progress.
+func GetArchiveMp4(w http.ResponseWriter, r *http.Request) {
+	fps := conf.DefaultVideoFps
+	if v := r.URL.Query().Get("fps"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			fps = parsed
+		}
+	}
+	dimensions, ok := video.Resolutions[r.URL.Query().Get("resolution")]
+	if !ok {
+		dimensions = video.Resolutions[conf.DefaultVideoResolution]
+	}
+
+	encoder := video.NewEncoder()
+	if encoder == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": video.ErrNoEncoderAvailable.Error()})
+		return
+	}
+
+	strFiles, _ := requestedFilesToRelativePaths(r.URL.Query()["f"]) // TODO handle error
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer pw.Close()
+		progress := startVideoEncodeProgress()
+		if err := encoder.Encode(strFiles, fps, dimensions[0], dimensions[1], pw, progress); err != nil {
+			log.Println("failed to encode video", err.Error())
+		}
+	}()
+
+	w.Header().Add(conf.HeaderContentType, "video/mp4")
+	w.Header().Set(conf.HeaderContentDisposition, "attachment; filename=archive.mp4")
+
+	writePipeContent(w, pr)
+}
+
+// GetArchiveMp4Progress streams the progress of the most recent /archive/mp4
+// encode as Server-Sent Events until it completes.
+func GetArchiveMp4Progress(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(conf.HeaderContentType, "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	for {
+		videoEncodeProgress.mu.RLock()
+		progress, done := videoEncodeProgress.current, videoEncodeProgress.done
+		videoEncodeProgress.mu.RUnlock()
+
+		fmt.Fprintf(w, "data: %.2f\n\n", progress)
+		flusher.Flush()
+		if done {
+			return
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
 func DeleteFiles(w http.ResponseWriter, r *http.Request) {
 	filesToDelete := r.URL.Query()["f"]
 	filesToDeleteAreProvided := len(filesToDelete) > 0
@@ -207,9 +630,14 @@ func DeleteFiles(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, len(filesToDelete))
 }
 
-func Admin(_ http.ResponseWriter, r *http.Request) {
+func Admin(w http.ResponseWriter, r *http.Request) {
 	command := pat.Param(r, "command")
-	admin.HandleCommand(command)
+	if err := admin.HandleCommand(command); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Failed to run admin command: %s", err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusOK)
 }
 
 func serveFileContent(w http.ResponseWriter, path string) {
@@ -250,6 +678,32 @@ func updatePartialConfiguration(updateRequest UpdateConfigurationRequest) (*conf
 	s.ResolutionSetting = updateRequest.ResolutionSetting
 	s.SecondsBetweenCaptures = updateRequest.SecondsBetweenCaptures
 	s.ObjectDetectionEnabled = updateRequest.ObjectDetectionEnabled
+	s.UseOpenCVDetection = updateRequest.UseOpenCVDetection
+	s.DetectionModelPath = updateRequest.DetectionModelPath
+	s.DetectionModelConfigPath = updateRequest.DetectionModelConfigPath
+	s.DetectionModelClassesPath = updateRequest.DetectionModelClassesPath
+	s.DetectionInputSize = updateRequest.DetectionInputSize
+	s.DetectionConfidenceThreshold = updateRequest.DetectionConfidenceThreshold
+	s.DetectionNMSThreshold = updateRequest.DetectionNMSThreshold
+	s.CameraMake = updateRequest.CameraMake
+	s.CameraModel = updateRequest.CameraModel
+	s.GPSEnabled = updateRequest.GPSEnabled
+	s.GPSLatitude = updateRequest.GPSLatitude
+	s.GPSLongitude = updateRequest.GPSLongitude
+	s.MotionCaptureEnabled = updateRequest.MotionCaptureEnabled
+	s.MotionGridCols = updateRequest.MotionGridCols
+	s.MotionGridRows = updateRequest.MotionGridRows
+	s.MotionCellDeltaThreshold = updateRequest.MotionCellDeltaThreshold
+	s.MotionChangedCellRatio = updateRequest.MotionChangedCellRatio
+	s.MotionCooldownSeconds = updateRequest.MotionCooldownSeconds
+	s.PublishEnabled = updateRequest.PublishEnabled
+	s.PublishDomain = updateRequest.PublishDomain
+	s.PublishActorHandle = updateRequest.PublishActorHandle
+	s.PublishFollowedInstanceAllowlist = updateRequest.PublishFollowedInstanceAllowlist
+	s.PublishVisibility = updateRequest.PublishVisibility
+	s.PublishAttachDetectionSummary = updateRequest.PublishAttachDetectionSummary
+	s.EventsBackend = updateRequest.EventsBackend
+	s.EventsMemoryCapacity = updateRequest.EventsMemoryCapacity
 	switch s.ResolutionSetting {
 	case 2:
 		s.PhotoResolutionWidth, s.PhotoResolutionHeight = 1640, 1232
@@ -302,7 +756,7 @@ func requestedFilesToRelativePaths(filteredFiles []string) ([]string, error) {
 		}
 	}
 
-	filesToArchive, err := files.ListFiles(conf.StorageFolder, true)
+	filesToArchive, err := files.ListFiles(conf.StorageFolder, true, false)
 	if err != nil {
 		return nil, err
 	}
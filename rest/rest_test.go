@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"net/http/httptest"
 	"testing"
 
 	"github.com/facebookgo/ensure"
@@ -11,3 +12,12 @@ func TestGetBasename(t *testing.T) {
 	ensure.DeepEqual(t, "foo", getBasename(conf.StorageFolder+"/foo"))
 	ensure.DeepEqual(t, "foo", getBasename("foo"))
 }
+
+func TestGetArchiveProgressUnknownID(t *testing.T) {
+	r := httptest.NewRequest("GET", "/archive/progress?id=does-not-exist", nil)
+	w := httptest.NewRecorder()
+
+	GetArchiveProgress(w, r)
+
+	ensure.DeepEqual(t, 404, w.Code)
+}
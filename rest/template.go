@@ -15,7 +15,6 @@ import (
 
 func GetMonitoring(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("content-type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*") // TODO limit to dev mode
 	json.NewEncoder(w).Encode(&MonitoringResponse{
 		Time:           admin.RunCommandOrPanic("/bin/date"),
 		GpuTemperature: admin.RunCommandOrPanic("/opt/vc/bin/vcgencmd", "measure_temp"),
@@ -27,12 +26,11 @@ func GetMonitoring(w http.ResponseWriter, _ *http.Request) {
 
 func GetPhotos(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("content-type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*") // TODO limit to dev mode
 	json.NewEncoder(w).Encode(getPhotosFrom(conf.StorageFolder))
 }
 
 func getPhotosFrom(folder string) GetPhotosResponse {
-	files, _ := files.ListFiles(folder, true) // TODO handle error
+	files, _ := files.ListFiles(folder, true, false) // TODO handle error
 	photos := make([]Photo, len(files))
 	for i, f := range files {
 		photos[i] = Photo{
@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/video"
+	"goji.io/pat"
+)
+
+// renderer backs the async /video/render, /video/jobs/:id and /video/:id
+// routes. It's a package-level singleton (mirroring archiveJobs) since job
+// state is persisted under conf.StorageFolder/renders/ and shared by every
+// request regardless of which goroutine handles it.
+var renderer = video.NewRenderer(conf.StorageFolder, renderMaxDuration())
+
+// renderMaxDuration reads the configured watchdog budget (see
+// conf.Settings.RenderMaxDurationSeconds) at startup. video.NewRenderer
+// falls back to conf.DefaultRenderMaxDurationSeconds if this is zero, e.g.
+// because settings couldn't be loaded yet.
+func renderMaxDuration() time.Duration {
+	settings, err := conf.LoadConfiguration()
+	if err != nil {
+		return 0
+	}
+	return time.Duration(settings.RenderMaxDurationSeconds) * time.Second
+}
+
+// PostVideoRender starts an asynchronous render assembling captured photos
+// into an MP4/WebM via ffmpeg (see the video package), returning its job ID
+// immediately. Progress is then available via GetVideoJob, and the
+// finished file via GetVideo, once Status is video.StatusDone.
+func PostVideoRender(w http.ResponseWriter, r *http.Request) {
+	var request video.RenderRequest
+	if err := parseJSON(r.Body, &request); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	job, err := renderer.Start(request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// GetVideoJob reports the status and progress of a render job started via
+// PostVideoRender, parsed from ffmpeg's stderr "frame=" output.
+func GetVideoJob(w http.ResponseWriter, r *http.Request) {
+	id := pat.Param(r, "id")
+
+	job, ok, err := renderer.Job(id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// GetVideo streams the finished video for a render job started via
+// PostVideoRender. It 404s if the job doesn't exist or hasn't reached
+// video.StatusDone yet.
+func GetVideo(w http.ResponseWriter, r *http.Request) {
+	id := pat.Param(r, "id")
+
+	job, ok, err := renderer.Job(id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if !ok || job.Status != video.StatusDone {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	serveFileContent(w, renderer.OutputPath(job))
+}
@@ -12,11 +12,25 @@ $(function() {
 		var currentInitialOffset = config.OffsetWithinHour == -1 ? -1 : config.OffsetWithinHour / 60;
 		var resolution = config.ResolutionSetting;
 		var rotation = config.RotateBy;
+		var detectionModel = config.DetectionModelPath;
 
 		$("#frequency").val(currentTimeBetween);
 		$("#offset").val(currentInitialOffset);
 		$("#rotation").val(rotation);
 		$("#resolution").val(resolution);
+		$("#detectionModel").val(detectionModel);
+		$("#motionCaptureEnabled").prop("checked", config.MotionCaptureEnabled);
+		$("#motionGridCols").val(config.MotionGridCols);
+		$("#motionGridRows").val(config.MotionGridRows);
+		$("#motionCellDeltaThreshold").val(config.MotionCellDeltaThreshold);
+		$("#motionChangedCellRatio").val(config.MotionChangedCellRatio);
+		$("#motionCooldownSeconds").val(config.MotionCooldownSeconds);
+		$("#publishEnabled").prop("checked", config.PublishEnabled);
+		$("#publishDomain").val(config.PublishDomain);
+		$("#publishActorHandle").val(config.PublishActorHandle);
+		$("#publishFollowedInstanceAllowlist").val(config.PublishFollowedInstanceAllowlist);
+		$("#publishVisibility").val(config.PublishVisibility);
+		$("#publishAttachDetectionSummary").prop("checked", config.PublishAttachDetectionSummary);
 	});
 
 	$("#saveConfigBtn").click(function() {
@@ -26,15 +40,41 @@ $(function() {
 		var initialOffsetRaw = parseInt($("#offset").val());
 		var rotationRaw = parseInt($("#rotation").val());
 		var resolutionRaw = parseInt($("#resolution").val());
+		var detectionModelRaw = $("#detectionModel").val();
+		var motionCaptureEnabledRaw = $("#motionCaptureEnabled").prop("checked");
+		var motionGridColsRaw = parseInt($("#motionGridCols").val());
+		var motionGridRowsRaw = parseInt($("#motionGridRows").val());
+		var motionCellDeltaThresholdRaw = parseFloat($("#motionCellDeltaThreshold").val());
+		var motionChangedCellRatioRaw = parseFloat($("#motionChangedCellRatio").val());
+		var motionCooldownSecondsRaw = parseInt($("#motionCooldownSeconds").val());
+		var publishEnabledRaw = $("#publishEnabled").prop("checked");
+		var publishDomainRaw = $("#publishDomain").val();
+		var publishActorHandleRaw = $("#publishActorHandle").val();
+		var publishFollowedInstanceAllowlistRaw = $("#publishFollowedInstanceAllowlist").val();
+		var publishVisibilityRaw = $("#publishVisibility").val();
+		var publishAttachDetectionSummaryRaw = $("#publishAttachDetectionSummary").prop("checked");
 
 		var timeBetween = 60 * timeBetweenRaw;
 		var initialOffset = initialOffsetRaw == -1 ? -1 : 60 * initialOffsetRaw;
 
 		var updatedConf = {
-			timeBetween:   timeBetween,
-			initialOffset: initialOffset,
-			resolution:    resolutionRaw,
-			rotateBy:      rotationRaw
+			timeBetween:             timeBetween,
+			initialOffset:           initialOffset,
+			resolution:              resolutionRaw,
+			rotateBy:                rotationRaw,
+			detectionModelPath:      detectionModelRaw,
+			motionCaptureEnabled:    motionCaptureEnabledRaw,
+			motionGridCols:          motionGridColsRaw,
+			motionGridRows:          motionGridRowsRaw,
+			motionCellDeltaThreshold: motionCellDeltaThresholdRaw,
+			motionChangedCellRatio:  motionChangedCellRatioRaw,
+			motionCooldownSeconds:   motionCooldownSecondsRaw,
+			publishEnabled:          publishEnabledRaw,
+			publishDomain:           publishDomainRaw,
+			publishActorHandle:      publishActorHandleRaw,
+			publishFollowedInstanceAllowlist: publishFollowedInstanceAllowlistRaw,
+			publishVisibility:       publishVisibilityRaw,
+			publishAttachDetectionSummary:    publishAttachDetectionSummaryRaw
 		};
 		console.log("Updating config to");
 		console.log(updatedConf);
@@ -86,6 +86,81 @@ const Html = `
 							</select>
 						</td>
 					</tr>
+					<tr>
+						<td>Object detection model:</td>
+						<td>
+							<select id="detectionModel">
+								<option value="">Disabled / auto-detect (/opt/yolo)</option>
+								<option value="/opt/yolo/yolov3-tiny.weights">YOLOv3-tiny (Darknet)</option>
+								<option value="/opt/yolo/yolov4-tiny.weights">YOLOv4-tiny (Darknet)</option>
+								<option value="/opt/yolo/yolov8n.onnx">YOLOv8n (ONNX)</option>
+							</select>
+							<em>Switches the installed model used by the native OpenCV detector without restarting the process.</em>
+						</td>
+					</tr>
+					<tr>
+						<td>Motion-triggered capture:</td>
+						<td>
+							<input type="checkbox" id="motionCaptureEnabled" />
+							<label for="motionCaptureEnabled">Only keep frames with detected motion</label>
+						</td>
+					</tr>
+					<tr>
+						<td>Motion grid (cols x rows):</td>
+						<td>
+							<input type="number" id="motionGridCols" min="1" max="64" style="width:4em;" /> x
+							<input type="number" id="motionGridRows" min="1" max="64" style="width:4em;" />
+						</td>
+					</tr>
+					<tr>
+						<td>Motion sensitivity:</td>
+						<td>
+							<input type="number" id="motionCellDeltaThreshold" min="0" max="255" style="width:5em;" /> per-cell delta,
+							<input type="number" id="motionChangedCellRatio" min="0" max="1" step="0.01" style="width:5em;" /> changed-cell ratio
+						</td>
+					</tr>
+					<tr>
+						<td>Motion cooldown (seconds):</td>
+						<td><input type="number" id="motionCooldownSeconds" min="0" style="width:5em;" /></td>
+					</tr>
+					<tr>
+						<td>ActivityPub/Fediverse publishing:</td>
+						<td>
+							<input type="checkbox" id="publishEnabled" />
+							<label for="publishEnabled">Announce new captures to followers</label>
+						</td>
+					</tr>
+					<tr>
+						<td>Publish domain:</td>
+						<td><input type="text" id="publishDomain" placeholder="timelapse.example.com" style="width:16em;" /></td>
+					</tr>
+					<tr>
+						<td>Publish actor handle:</td>
+						<td><input type="text" id="publishActorHandle" style="width:10em;" /></td>
+					</tr>
+					<tr>
+						<td>Followed instance allowlist:</td>
+						<td>
+							<input type="text" id="publishFollowedInstanceAllowlist" placeholder="mastodon.social,example.social" style="width:16em;" />
+							<em>Comma-separated hostnames allowed to follow. Empty allows any instance.</em>
+						</td>
+					</tr>
+					<tr>
+						<td>Post visibility:</td>
+						<td>
+							<select id="publishVisibility">
+								<option value="public">Public</option>
+								<option value="unlisted">Unlisted</option>
+							</select>
+						</td>
+					</tr>
+					<tr>
+						<td>Attach detection summary:</td>
+						<td>
+							<input type="checkbox" id="publishAttachDetectionSummary" />
+							<label for="publishAttachDetectionSummary">Include object detection summary in posts</label>
+						</td>
+					</tr>
 					<tr>
 						<td colspan="2">
 							<input type="button" id="saveConfigBtn" value="Save" />
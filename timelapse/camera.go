@@ -3,6 +3,7 @@ package timelapse
 // This has been adapted from https://github.com/loranbriggs/go-camera
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -16,29 +17,120 @@ import (
 
 const (
 	commandRaspistill = "rpicam-still"
+	commandLibcamera  = "libcamera-still"
 	commandFfmpeg     = "ffmpeg"
 )
 
-type Camera struct {
+// Backend selectors for NewCamera, persisted as conf.Settings.CaptureBackend.
+const (
+	BackendAuto       = "auto"
+	BackendRpicam     = "rpicam"
+	BackendLibcamera  = "libcamera"
+	BackendFfmpegV4L2 = "ffmpeg-v4l2"
+	BackendRTSP       = "rtsp"
+)
+
+// BackendInfo identifies which concrete Capturer backend is in use, for
+// diagnostics.
+type BackendInfo struct {
+	Name string
+}
+
+// Capturer captures a single photo to disk. Concrete implementations wrap
+// whichever command-line tool or protocol can reach the actual camera
+// hardware; see NewCamera for backend selection.
+type Capturer interface {
+	// Capture takes one photo, respecting ctx's deadline, and returns its
+	// path on disk.
+	Capture(ctx context.Context) (string, error)
+	// Close releases any resources held by the Capturer.
+	Close() error
+	// Describe reports which backend is in use.
+	Describe() BackendInfo
+}
+
+// cameraOptions holds the settings shared by the still-image backends that
+// capture from locally attached hardware (rpicam-still, libcamera-still,
+// and ffmpeg against a V4L2 webcam).
+type cameraOptions struct {
 	savePath                         string
 	width, height                    int
 	flipHorizontally, flipVertically bool
 	quality                          int
+	tuning                           StillTuning
+}
+
+// StillTuning holds the optional rpicam-still/libcamera-still image-tuning
+// parameters (conf.Settings' Contrast/Saturation/... fields), for astro/
+// night timelapses that need finer exposure control than width/height/
+// quality alone. Only RpicamStillCapturer and LibcameraStillCapturer honor
+// it; FfmpegV4L2Capturer and RTSPCapturer ignore it. A zero value for a
+// numeric field, or an empty string, leaves the tool's own default in place.
+type StillTuning struct {
+	Contrast       float64
+	Saturation     float64
+	Sharpness      float64
+	Brightness     float64
+	ExposureMode   string // e.g. "auto", "night", "sports"
+	AWBMode        string
+	ISO            int
+	ShutterSpeedUs int
+	EVCompensation float64
+	Denoise        string // e.g. "off", "cdn_fast", "cdn_hq"
+	// TextOverlay is passed straight through to --annotate, which supports
+	// strftime tokens (e.g. "%Y-%m-%d %H:%M:%S") natively.
+	TextOverlay string
 }
 
-// NewCamera Setting "rotate" to true will create a camera instance which will flip all pictures by 180 degrees. Each captured image will be flipped horizontally and vertically.
-func NewCamera(path string, width, height int, rotate bool, quality int) (Camera, error) {
+// NewCamera selects and constructs the Capturer named by backend: "rpicam",
+// "libcamera", "ffmpeg-v4l2", or "rtsp". "auto" (and any unrecognized value,
+// mirroring events.New's fallback behavior) preserves this project's
+// original behavior: rpicam-still on ARM (Raspberry Pi), ffmpeg against a
+// USB webcam everywhere else. rtspURL is only required for BackendRTSP.
+//
+// Setting "rotate" to true will create a camera instance which will flip all
+// pictures by 180 degrees. Each captured image will be flipped horizontally
+// and vertically.
+//
+// tuning is only applied by the rpicam/libcamera backends; it's ignored by
+// ffmpeg-v4l2 and rtsp.
+func NewCamera(path string, width, height int, rotate bool, quality int, backend, rtspURL string, tuning StillTuning) (Capturer, error) {
+	if backend == BackendRTSP {
+		if rtspURL == "" {
+			return nil, errors.New("invalid config: rtspURL must not be empty")
+		}
+		if path == "" {
+			return nil, errors.New("invalid config: path must not be empty")
+		}
+		return &RTSPCapturer{savePath: path, url: rtspURL}, nil
+	}
+
 	if path == "" {
-		return Camera{}, errors.New("invalid config: path must not be empty")
+		return nil, errors.New("invalid config: path must not be empty")
 	}
-	return Camera{
+	opts := cameraOptions{
 		savePath:         path,
 		width:            width,
 		height:           height,
 		flipHorizontally: rotate,
 		flipVertically:   rotate,
 		quality:          quality,
-	}, nil
+		tuning:           tuning,
+	}
+
+	switch backend {
+	case BackendRpicam:
+		return &RpicamStillCapturer{opts}, nil
+	case BackendLibcamera:
+		return &LibcameraStillCapturer{opts}, nil
+	case BackendFfmpegV4L2:
+		return &FfmpegV4L2Capturer{opts}, nil
+	default:
+		if isDevelopment() {
+			return &FfmpegV4L2Capturer{opts}, nil
+		}
+		return &RpicamStillCapturer{opts}, nil
+	}
 }
 
 // isDevelopment detects whether the code is running on a development system (non-ARM)
@@ -51,52 +143,157 @@ func isDevelopment() bool {
 	return arch != "arm" && arch != "arm64"
 }
 
-func (c *Camera) Capture() (string, error) {
-	fullPath := c.getAbsoluteFilepath()
-	
-	if isDevelopment() {
-		// On development systems, use ffmpeg to capture from webcam
-		return c.captureWithWebcam(fullPath)
-	}
-	
-	// On Raspberry Pi, use rpicam-still
-	args := c.getRaspistillArgs(fullPath)
-	log.Printf("Running command: %s %v", commandRaspistill, args)
-	cmd := exec.Command(commandRaspistill, args...)
+// RpicamStillCapturer captures photos using rpicam-still, the default on a
+// Raspberry Pi with a libcamera-compatible (HQ) camera module.
+type RpicamStillCapturer struct {
+	cameraOptions
+}
+
+// Capture takes a single photo, respecting ctx's deadline, and is paced by
+// the shared capturePacer so a scheduled capture and a REST-triggered
+// preview cannot fire the camera at the same time. It returns ErrCameraBusy
+// if the pacer could not admit the request before ctx expired, and
+// ErrCaptureTimeout if the underlying command was still running when ctx's
+// deadline passed.
+func (c *RpicamStillCapturer) Capture(ctx context.Context) (string, error) {
+	return capturePacer.call(ctx, c.captureNow)
+}
+
+func (c *RpicamStillCapturer) captureNow(ctx context.Context) (string, error) {
+	return runStillCommand(ctx, commandRaspistill, c.cameraOptions)
+}
+
+func (c *RpicamStillCapturer) Close() error { return nil }
+
+func (c *RpicamStillCapturer) Describe() BackendInfo { return BackendInfo{Name: BackendRpicam} }
+
+// LibcameraStillCapturer captures photos using libcamera-still, the
+// predecessor to rpicam-still kept for Pi OS releases that still ship it
+// under the old name.
+type LibcameraStillCapturer struct {
+	cameraOptions
+}
+
+func (c *LibcameraStillCapturer) Capture(ctx context.Context) (string, error) {
+	return capturePacer.call(ctx, c.captureNow)
+}
+
+func (c *LibcameraStillCapturer) captureNow(ctx context.Context) (string, error) {
+	return runStillCommand(ctx, commandLibcamera, c.cameraOptions)
+}
+
+func (c *LibcameraStillCapturer) Close() error { return nil }
+
+func (c *LibcameraStillCapturer) Describe() BackendInfo {
+	return BackendInfo{Name: BackendLibcamera}
+}
+
+// runStillCommand runs a rpicam-still-compatible command (rpicam-still and
+// libcamera-still share the same flag set) and waits for it to produce
+// opts.savePath's next file.
+func runStillCommand(ctx context.Context, command string, opts cameraOptions) (string, error) {
+	DeviceLock.Lock()
+	defer DeviceLock.Unlock()
+
+	fullPath := opts.getAbsoluteFilepath()
+	args := opts.stillArgs(fullPath)
+	log.Printf("Running command: %s %v", command, args)
+	cmd := exec.CommandContext(ctx, command, args...)
 
 	_, err := cmd.StdoutPipe()
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
-	err = cmd.Start()
-	if err != nil {
+	if err := cmd.Start(); err != nil {
+		log.Println(err)
+		return "", err
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", ErrCaptureTimeout
+		}
 		log.Println(err)
 		return "", err
 	}
-	cmd.Wait()
 	return fullPath, nil
 }
 
-func (c *Camera) getRaspistillArgs(fullPath string) []string {
+func (o cameraOptions) stillArgs(fullPath string) []string {
 	args := []string{
-		"--width", strconv.Itoa(c.width),
-		"--height", strconv.Itoa(c.height),
-		"--quality", strconv.Itoa(c.quality),
+		"--width", strconv.Itoa(o.width),
+		"--height", strconv.Itoa(o.height),
+		"--quality", strconv.Itoa(o.quality),
 	}
-	if c.flipVertically {
+	if o.flipVertically {
 		args = append(args, "--vflip")
 	}
-	if c.flipHorizontally {
+	if o.flipHorizontally {
 		args = append(args, "--hflip")
 	}
+	args = append(args, o.tuning.args()...)
 	return append(args, "--output", fullPath)
 }
 
-// captureWithWebcam captures an image from the first available webcam using ffmpeg.
-// This is used on development systems where raspistill is not available.
-func (c *Camera) captureWithWebcam(fullPath string) (string, error) {
-	// Try to capture from /dev/video0 (first webcam)
+// args renders t as the corresponding rpicam-still/libcamera-still flags.
+// Zero/empty fields are omitted so the tool's own default takes over.
+func (t StillTuning) args() []string {
+	var args []string
+	if t.Contrast != 0 {
+		args = append(args, "--contrast", strconv.FormatFloat(t.Contrast, 'f', -1, 64))
+	}
+	if t.Saturation != 0 {
+		args = append(args, "--saturation", strconv.FormatFloat(t.Saturation, 'f', -1, 64))
+	}
+	if t.Sharpness != 0 {
+		args = append(args, "--sharpness", strconv.FormatFloat(t.Sharpness, 'f', -1, 64))
+	}
+	if t.Brightness != 0 {
+		args = append(args, "--brightness", strconv.FormatFloat(t.Brightness, 'f', -1, 64))
+	}
+	if t.ExposureMode != "" {
+		args = append(args, "--exposure", t.ExposureMode)
+	}
+	if t.AWBMode != "" {
+		args = append(args, "--awb", t.AWBMode)
+	}
+	if t.ISO != 0 {
+		// rpicam-still has no direct --ISO flag; --gain is its analogue-gain
+		// equivalent, with ISO 100 corresponding to unity gain.
+		args = append(args, "--gain", strconv.FormatFloat(float64(t.ISO)/100.0, 'f', -1, 64))
+	}
+	if t.ShutterSpeedUs != 0 {
+		args = append(args, "--shutter", strconv.Itoa(t.ShutterSpeedUs))
+	}
+	if t.EVCompensation != 0 {
+		args = append(args, "--ev", strconv.FormatFloat(t.EVCompensation, 'f', -1, 64))
+	}
+	if t.Denoise != "" {
+		args = append(args, "--denoise", t.Denoise)
+	}
+	if t.TextOverlay != "" {
+		args = append(args, "--annotate", t.TextOverlay)
+	}
+	return args
+}
+
+// FfmpegV4L2Capturer captures photos from the first available USB webcam
+// (/dev/video0) using ffmpeg. This is used on development systems where
+// rpicam-still/libcamera-still aren't available.
+type FfmpegV4L2Capturer struct {
+	cameraOptions
+}
+
+func (c *FfmpegV4L2Capturer) Capture(ctx context.Context) (string, error) {
+	return capturePacer.call(ctx, c.captureNow)
+}
+
+func (c *FfmpegV4L2Capturer) captureNow(ctx context.Context) (string, error) {
+	DeviceLock.Lock()
+	defer DeviceLock.Unlock()
+
+	fullPath := c.getAbsoluteFilepath()
+
 	// ffmpeg -f v4l2 -video_size WIDTHxHEIGHT -i /dev/video0 -frames:v 1 -q:v QUALITY output.jpg
 	args := []string{
 		"-f", "v4l2",
@@ -105,8 +302,7 @@ func (c *Camera) captureWithWebcam(fullPath string) (string, error) {
 		"-frames:v", "1",
 		"-q:v", strconv.Itoa(c.quality),
 	}
-	
-	// Add flip filters if needed
+
 	var filters []string
 	if c.flipHorizontally {
 		filters = append(filters, "hflip")
@@ -117,26 +313,77 @@ func (c *Camera) captureWithWebcam(fullPath string) (string, error) {
 	if len(filters) > 0 {
 		args = append(args, "-vf", strings.Join(filters, ","))
 	}
-	
+
 	args = append(args, "-y", fullPath)
-	
+
 	log.Printf("Running command: %s %v", commandFfmpeg, args)
-	cmd := exec.Command(commandFfmpeg, args...)
-	
+	cmd := exec.CommandContext(ctx, commandFfmpeg, args...)
+
 	// Capture stderr since ffmpeg outputs to stderr
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", ErrCaptureTimeout
+		}
 		log.Printf("ffmpeg error: %s", string(output))
 		return "", fmt.Errorf("failed to capture from webcam: %w", err)
 	}
-	
+
+	return fullPath, nil
+}
+
+func (c *FfmpegV4L2Capturer) Close() error { return nil }
+
+func (c *FfmpegV4L2Capturer) Describe() BackendInfo {
+	return BackendInfo{Name: BackendFfmpegV4L2}
+}
+
+// RTSPCapturer grabs a single frame from a networked IP camera's RTSP
+// stream using ffmpeg, so the same daemon can drive a Pi HQ camera, a USB
+// webcam, or an RTSP-speaking IP camera without code changes.
+type RTSPCapturer struct {
+	savePath string
+	url      string
+}
+
+func (c *RTSPCapturer) Capture(ctx context.Context) (string, error) {
+	return capturePacer.call(ctx, c.captureNow)
+}
+
+func (c *RTSPCapturer) captureNow(ctx context.Context) (string, error) {
+	fullPath := filepath.Join(c.savePath, getFileName(time.Now()))
+
+	// ffmpeg -rtsp_transport tcp -i rtsp://... -frames:v 1 output.jpg
+	args := []string{
+		"-rtsp_transport", "tcp",
+		"-i", c.url,
+		"-frames:v", "1",
+		"-y", fullPath,
+	}
+
+	log.Printf("Running command: %s -rtsp_transport tcp -i <redacted> -frames:v 1 -y %s", commandFfmpeg, fullPath)
+	cmd := exec.CommandContext(ctx, commandFfmpeg, args...)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", ErrCaptureTimeout
+		}
+		log.Printf("ffmpeg error: %s", string(output))
+		return "", fmt.Errorf("failed to capture from RTSP stream: %w", err)
+	}
+
 	return fullPath, nil
 }
 
+func (c *RTSPCapturer) Close() error { return nil }
+
+func (c *RTSPCapturer) Describe() BackendInfo { return BackendInfo{Name: BackendRTSP} }
+
 func getFileName(t time.Time) string {
 	return fmt.Sprintf("%4d%02d%02d-%02d%02d%02d.jpg", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
 }
 
-func (c *Camera) getAbsoluteFilepath() string {
-	return filepath.Join(c.savePath, getFileName(time.Now()))
+func (o cameraOptions) getAbsoluteFilepath() string {
+	return filepath.Join(o.savePath, getFileName(time.Now()))
 }
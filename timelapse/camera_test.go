@@ -10,34 +10,78 @@ import (
 )
 
 func TestCreateCameraWithoutPath(t *testing.T) {
-	_, err := NewCamera("", 200, 100, false, 100)
+	_, err := NewCamera("", 200, 100, false, 100, BackendAuto, "", StillTuning{})
 	// Should have failed since path must not be empty.
 	ensure.NotNil(t, err)
 }
 
+func TestCreateRTSPCameraWithoutURL(t *testing.T) {
+	_, err := NewCamera("foo", 200, 100, false, 100, BackendRTSP, "", StillTuning{})
+	// Should have failed since rtspURL must not be empty.
+	ensure.NotNil(t, err)
+}
+
 func TestBuildingArguments(t *testing.T) {
-	unrotatedCamera, err := NewCamera("foo", 200, 100, false, 100)
+	unrotatedCamera, err := NewCamera("foo", 200, 100, false, 100, BackendRpicam, "", StillTuning{})
 	ensure.Nil(t, err)
-	ensure.False(t, unrotatedCamera.flipVertically)
-	ensure.False(t, unrotatedCamera.flipHorizontally)
+	rpicam, ok := unrotatedCamera.(*RpicamStillCapturer)
+	ensure.True(t, ok)
+	ensure.False(t, rpicam.flipVertically)
+	ensure.False(t, rpicam.flipHorizontally)
 
-	p := unrotatedCamera.getAbsoluteFilepath()
+	p := rpicam.getAbsoluteFilepath()
 	// Example: "foo/20210913-184442.jpg"
 	ensure.DeepEqual(t, 0, strings.Index(p, "foo/"))
 	ensure.DeepEqual(t, strings.Index(p, ".jpg"), 19)
 }
 
-func TestRaspistillArgs(t *testing.T) {
-	unrotatedCamera, _ := NewCamera("foo", 200, 100, false, 100)
-	args := unrotatedCamera.getRaspistillArgs("foo/someFile.jpg")
+func TestStillArgs(t *testing.T) {
+	unrotatedCamera, _ := NewCamera("foo", 200, 100, false, 100, BackendRpicam, "", StillTuning{})
+	rpicam := unrotatedCamera.(*RpicamStillCapturer)
+	args := rpicam.stillArgs("foo/someFile.jpg")
 	ensure.DeepEqual(t, []string{"--width", "200", "--height", "100", "--quality", "100", "--output", "foo/someFile.jpg"}, args)
 }
 
+func TestStillArgsWithTuning(t *testing.T) {
+	camera, _ := NewCamera("foo", 200, 100, false, 100, BackendRpicam, "", StillTuning{
+		Contrast:       1.5,
+		Saturation:     1.2,
+		Sharpness:      2,
+		Brightness:     0.1,
+		ExposureMode:   "night",
+		AWBMode:        "tungsten",
+		ISO:            800,
+		ShutterSpeedUs: 250000,
+		EVCompensation: -0.5,
+		Denoise:        "cdn_hq",
+		TextOverlay:    "%Y-%m-%d %H:%M:%S",
+	})
+	rpicam := camera.(*RpicamStillCapturer)
+	args := rpicam.stillArgs("foo/someFile.jpg")
+	expected := []string{
+		"--width", "200", "--height", "100", "--quality", "100",
+		"--contrast", "1.5",
+		"--saturation", "1.2",
+		"--sharpness", "2",
+		"--brightness", "0.1",
+		"--exposure", "night",
+		"--awb", "tungsten",
+		"--gain", "8",
+		"--shutter", "250000",
+		"--ev", "-0.5",
+		"--denoise", "cdn_hq",
+		"--annotate", "%Y-%m-%d %H:%M:%S",
+		"--output", "foo/someFile.jpg",
+	}
+	ensure.DeepEqual(t, expected, args)
+}
+
 func TestCreateRotatedCamera(t *testing.T) {
-	rotatedCamera, err := NewCamera("foo", 200, 100, true, 100)
+	rotatedCamera, err := NewCamera("foo", 200, 100, true, 100, BackendRpicam, "", StillTuning{})
 	ensure.Nil(t, err)
-	ensure.True(t, rotatedCamera.flipVertically)
-	ensure.True(t, rotatedCamera.flipHorizontally)
+	rpicam := rotatedCamera.(*RpicamStillCapturer)
+	ensure.True(t, rpicam.flipVertically)
+	ensure.True(t, rpicam.flipHorizontally)
 }
 
 func TestCreateFileName(t *testing.T) {
@@ -51,7 +95,7 @@ func TestIsDevelopment(t *testing.T) {
 	// Test that isDevelopment returns true for non-ARM architectures
 	arch := runtime.GOARCH
 	result := isDevelopment()
-	
+
 	if arch == "arm" || arch == "arm64" {
 		ensure.False(t, result)
 	} else {
@@ -60,16 +104,42 @@ func TestIsDevelopment(t *testing.T) {
 	}
 }
 
-func TestWebcamCaptureArgsBasic(t *testing.T) {
-	camera, _ := NewCamera("/tmp", 640, 480, false, 75)
-	// We can't fully test captureWithWebcam without an actual webcam,
-	// but we can verify the basic structure exists and doesn't panic
-	ensure.NotNil(t, camera)
+func TestAutoBackendSelectsByArchitecture(t *testing.T) {
+	camera, err := NewCamera("/tmp", 640, 480, false, 75, BackendAuto, "", StillTuning{})
+	ensure.Nil(t, err)
+	if isDevelopment() {
+		_, ok := camera.(*FfmpegV4L2Capturer)
+		ensure.True(t, ok)
+	} else {
+		_, ok := camera.(*RpicamStillCapturer)
+		ensure.True(t, ok)
+	}
+}
+
+func TestUnrecognizedBackendFallsBackToAuto(t *testing.T) {
+	camera, err := NewCamera("/tmp", 640, 480, false, 75, "not-a-real-backend", "", StillTuning{})
+	ensure.Nil(t, err)
+	autoCamera, err := NewCamera("/tmp", 640, 480, false, 75, BackendAuto, "", StillTuning{})
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, autoCamera.Describe(), camera.Describe())
 }
 
 func TestWebcamCaptureArgsWithRotation(t *testing.T) {
-	camera, _ := NewCamera("/tmp", 1920, 1080, true, 90)
-	// Verify the camera is created with rotation settings
-	ensure.True(t, camera.flipHorizontally)
-	ensure.True(t, camera.flipVertically)
+	camera, err := NewCamera("/tmp", 1920, 1080, true, 90, BackendFfmpegV4L2, "", StillTuning{})
+	ensure.Nil(t, err)
+	ffmpeg := camera.(*FfmpegV4L2Capturer)
+	ensure.True(t, ffmpeg.flipHorizontally)
+	ensure.True(t, ffmpeg.flipVertically)
+}
+
+func TestLibcameraBackendDescribe(t *testing.T) {
+	camera, err := NewCamera("/tmp", 640, 480, false, 75, BackendLibcamera, "", StillTuning{})
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, BackendInfo{Name: BackendLibcamera}, camera.Describe())
+}
+
+func TestRTSPCaptureDescribe(t *testing.T) {
+	camera, err := NewCamera("/tmp", 0, 0, false, 0, BackendRTSP, "rtsp://example.com/stream", StillTuning{})
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, BackendInfo{Name: BackendRTSP}, camera.Describe())
 }
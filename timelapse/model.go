@@ -2,6 +2,7 @@ package timelapse
 
 import (
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/events"
 )
 
 // Monitor interface for system monitoring
@@ -14,4 +15,5 @@ type Timelapse struct {
 	Settings            conf.Settings
 	ConfigUpdateChannel <-chan conf.Settings
 	Monitor             Monitor
+	Eventer             events.Eventer
 }
@@ -0,0 +1,154 @@
+package timelapse
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+)
+
+// ErrCameraBusy is returned by Capturer.Capture when the shared pacer could not
+// admit the request before ctx expired, either because another capture was
+// still in flight or because the minimum interval/backoff window had not
+// elapsed yet.
+var ErrCameraBusy = errors.New("camera is busy, try again later")
+
+// ErrCaptureTimeout is returned by Capturer.Capture when the underlying
+// rpicam-still/libcamera-still/ffmpeg invocation did not finish before ctx's deadline.
+var ErrCaptureTimeout = errors.New("capture timed out")
+
+// initialBackoff is the backoff applied after the first failure, regardless
+// of minInterval, then doubled on each subsequent consecutive failure up to
+// maxBackoff.
+const initialBackoff = time.Second
+
+// pacer serializes camera invocations across every Camera instance and
+// enforces a minimum interval between them, backing off exponentially after
+// consecutive failures and resetting once a capture succeeds again. This is
+// the same min-sleep/max-sleep-with-backoff shape as rclone's fs.Pacer,
+// scaled down to the single shared resource a camera is.
+type pacer struct {
+	mu sync.Mutex
+
+	minInterval time.Duration
+	maxBackoff  time.Duration
+	backoff     time.Duration
+	nextAllowed time.Time
+
+	// admit serializes call end-to-end (wait, fn, and recordOutcome all run
+	// while holding it), so two concurrent callers can never both pass the
+	// nextAllowed check and fire the camera at the same time. It's a
+	// buffered channel rather than sync.Mutex so call can give up on it when
+	// ctx expires.
+	admit chan struct{}
+}
+
+func newPacer(minInterval, maxBackoff time.Duration) *pacer {
+	p := &pacer{
+		minInterval: minInterval,
+		maxBackoff:  maxBackoff,
+		admit:       make(chan struct{}, 1),
+	}
+	p.admit <- struct{}{}
+	return p
+}
+
+// configure updates the pacer's bounds in place, e.g. after a settings
+// update, without disturbing in-flight backoff state.
+func (p *pacer) configure(minInterval, maxBackoff time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.minInterval = minInterval
+	p.maxBackoff = maxBackoff
+	if p.backoff > p.maxBackoff {
+		p.backoff = p.maxBackoff
+	}
+}
+
+// call admits one caller at a time, waits for the pacer's min-interval/
+// backoff window to elapse, then invokes fn, honouring ctx cancellation
+// while queued for admission, while waiting, and during fn itself. It grows
+// the backoff on error (capped at maxBackoff) and resets it to zero on
+// success.
+func (p *pacer) call(ctx context.Context, fn func(ctx context.Context) (string, error)) (string, error) {
+	select {
+	case <-p.admit:
+	case <-ctx.Done():
+		return "", ErrCameraBusy
+	}
+	defer func() { p.admit <- struct{}{} }()
+
+	if err := p.wait(ctx); err != nil {
+		return "", err
+	}
+
+	path, err := fn(ctx)
+	p.recordOutcome(err)
+	return path, err
+}
+
+// recordOutcome grows the backoff on error (capped at maxBackoff, starting
+// from initialBackoff) and resets it to zero on success, then schedules
+// nextAllowed accordingly.
+func (p *pacer) recordOutcome(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		if p.backoff == 0 {
+			p.backoff = initialBackoff
+		} else {
+			p.backoff *= 2
+		}
+		if p.backoff > p.maxBackoff {
+			p.backoff = p.maxBackoff
+		}
+	} else {
+		p.backoff = 0
+	}
+	p.nextAllowed = time.Now().Add(p.minInterval + p.backoff)
+}
+
+// wait blocks, locking out concurrent callers, until nextAllowed has passed,
+// returning ErrCameraBusy if ctx expires first.
+func (p *pacer) wait(ctx context.Context) error {
+	p.mu.Lock()
+	wait := time.Until(p.nextAllowed)
+	p.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ErrCameraBusy
+	}
+}
+
+// capturePacer is shared across every Camera instance so that a
+// REST-triggered preview (rest.Capture) and the scheduled timelapse loop
+// cannot double-fire the camera or hammer it with back-to-back retries.
+var capturePacer = newPacer(
+	time.Duration(0)*time.Second,
+	time.Duration(conf.DefaultMaxBackoffSeconds)*time.Second,
+)
+
+// ConfigurePacer updates the shared capture pacer's bounds, e.g. in response
+// to a settings change or at startup.
+func ConfigurePacer(minInterval, maxBackoff time.Duration) {
+	capturePacer.configure(minInterval, maxBackoff)
+}
+
+// DeviceLock guards exclusive access to the physical capture device
+// (/dev/video0, or the CSI camera driven by rpicam-still/libcamera-still)
+// across packages: runStillCommand and FfmpegV4L2Capturer.captureNow (see
+// camera.go) hold it for the duration of each capture, so two captures can
+// never run concurrently and fight over the same hardware.
+var DeviceLock sync.Mutex
@@ -0,0 +1,108 @@
+package timelapse
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/facebookgo/ensure"
+)
+
+func TestPacerEnforcesMinInterval(t *testing.T) {
+	p := newPacer(50*time.Millisecond, time.Second)
+
+	calls := 0
+	ok := func(ctx context.Context) (string, error) {
+		calls++
+		return "ok", nil
+	}
+
+	_, err := p.call(context.Background(), ok)
+	ensure.Nil(t, err)
+
+	before := time.Now()
+	_, err = p.call(context.Background(), ok)
+	ensure.Nil(t, err)
+	ensure.True(t, time.Since(before) >= 50*time.Millisecond)
+	ensure.DeepEqual(t, 2, calls)
+}
+
+func TestPacerReturnsCameraBusyWhenContextExpiresWhileWaiting(t *testing.T) {
+	p := newPacer(time.Second, time.Second)
+
+	ok := func(ctx context.Context) (string, error) { return "ok", nil }
+	_, err := p.call(context.Background(), ok)
+	ensure.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = p.call(ctx, ok)
+	ensure.True(t, errors.Is(err, ErrCameraBusy))
+}
+
+func TestPacerGrowsAndResetsBackoff(t *testing.T) {
+	p := newPacer(0, time.Minute)
+
+	boom := errors.New("boom")
+	p.recordOutcome(boom)
+	ensure.DeepEqual(t, initialBackoff, p.backoff)
+
+	p.recordOutcome(boom)
+	ensure.DeepEqual(t, initialBackoff*2, p.backoff)
+
+	p.recordOutcome(nil)
+	ensure.DeepEqual(t, time.Duration(0), p.backoff)
+}
+
+func TestPacerCapsBackoffAtMax(t *testing.T) {
+	p := newPacer(0, 3*time.Second)
+
+	boom := errors.New("boom")
+	for i := 0; i < 10; i++ {
+		p.recordOutcome(boom)
+	}
+	ensure.DeepEqual(t, 3*time.Second, p.backoff)
+}
+
+func TestPacerSerializesConcurrentCallers(t *testing.T) {
+	p := newPacer(0, time.Second)
+
+	var inFlight int32
+	var maxInFlight int32
+	fn := func(ctx context.Context) (string, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return "ok", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.call(context.Background(), fn)
+			ensure.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	ensure.DeepEqual(t, int32(1), maxInFlight)
+}
+
+func TestConfigurePacerClampsInFlightBackoff(t *testing.T) {
+	p := newPacer(0, time.Minute)
+	p.backoff = 30 * time.Second
+
+	p.configure(0, time.Second)
+	ensure.True(t, p.backoff <= time.Second)
+}
@@ -0,0 +1,31 @@
+package timelapse
+
+import "time"
+
+// Scheduler computes capture target times on a fixed per-hour grid:
+// OffsetWithinHour, OffsetWithinHour+SecondsBetweenCaptures, ... up to but
+// not including the next hour, at which point the grid restarts at
+// OffsetWithinHour. This replaces sleep-and-recompute polling with absolute
+// target timestamps, so a run loop can detect an overrun (the target was
+// missed because the previous capture took too long) instead of drifting.
+type Scheduler struct {
+	SecondsBetweenCaptures int
+	OffsetWithinHour       int
+}
+
+// Next returns the earliest grid target at or after now. A now that falls
+// exactly on a grid slot returns that same slot, so repeatedly scheduling
+// off of a returned target never skips it.
+func (s Scheduler) Next(now time.Time) time.Time {
+	hourStart := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
+	offset := time.Duration(s.OffsetWithinHour%3600) * time.Second
+	period := time.Duration(s.SecondsBetweenCaptures) * time.Second
+
+	for slot := offset; slot < time.Hour; slot += period {
+		if target := hourStart.Add(slot); !target.Before(now) {
+			return target
+		}
+	}
+
+	return hourStart.Add(time.Hour).Add(offset)
+}
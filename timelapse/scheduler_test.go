@@ -0,0 +1,77 @@
+package timelapse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facebookgo/ensure"
+)
+
+func TestSchedulerNext_TableDriven(t *testing.T) {
+	s := Scheduler{SecondsBetweenCaptures: 30 * 60, OffsetWithinHour: 15 * 60}
+	loc := time.Now().Location()
+
+	cases := []struct {
+		now      abbrevTime
+		expected abbrevTime
+	}{
+		{abbrevTime{year: 2017, month: 12, day: 1, hour: 8, min: 46, sec: 1, location: loc}, abbrevTime{year: 2017, month: 12, day: 1, hour: 9, min: 15, location: loc}},
+		{abbrevTime{year: 2017, month: 12, day: 1, hour: 8, min: 32, sec: 1, location: loc}, abbrevTime{year: 2017, month: 12, day: 1, hour: 8, min: 45, location: loc}},
+		{abbrevTime{year: 2017, month: 12, day: 1, hour: 8, min: 16, sec: 1, location: loc}, abbrevTime{year: 2017, month: 12, day: 1, hour: 8, min: 45, location: loc}},
+		{abbrevTime{year: 2017, month: 12, day: 1, hour: 8, min: 8, sec: 1, location: loc}, abbrevTime{year: 2017, month: 12, day: 1, hour: 8, min: 15, location: loc}},
+	}
+
+	for _, c := range cases {
+		ensure.DeepEqual(t, s.Next(c.now.toDate()), c.expected.toDate())
+	}
+}
+
+func TestSchedulerNext_ExactBoundary(t *testing.T) {
+	s := Scheduler{SecondsBetweenCaptures: 30 * 60, OffsetWithinHour: 15 * 60}
+	now := time.Date(2017, time.December, 1, 8, 15, 0, 0, time.UTC)
+
+	// A now that falls exactly on a grid slot returns that same slot, not the
+	// following one.
+	ensure.DeepEqual(t, s.Next(now), now)
+}
+
+func TestSchedulerNext_Overrun(t *testing.T) {
+	s := Scheduler{SecondsBetweenCaptures: 60, OffsetWithinHour: 0}
+	hourStart := time.Date(2017, time.December, 1, 8, 0, 0, 0, time.UTC)
+
+	// A capture starting at slot 0 that overran into the middle of the slot
+	// at 2 minutes should jump straight to the slot at 3 minutes, not the
+	// slot it already missed.
+	now := hourStart.Add(125 * time.Second)
+	ensure.DeepEqual(t, s.Next(now), hourStart.Add(180*time.Second))
+}
+
+func TestSchedulerNext_ConfigChangeMidSleep(t *testing.T) {
+	now := time.Date(2017, time.December, 1, 8, 46, 1, 0, time.UTC)
+
+	oldScheduler := Scheduler{SecondsBetweenCaptures: 30 * 60, OffsetWithinHour: 15 * 60}
+	newScheduler := Scheduler{SecondsBetweenCaptures: 10 * 60, OffsetWithinHour: 0}
+
+	// Reconfiguring mid-sleep rebuilds the grid from the new settings rather
+	// than keeping the old target.
+	ensure.DeepEqual(t, oldScheduler.Next(now), time.Date(2017, time.December, 1, 9, 15, 0, 0, time.UTC))
+	ensure.DeepEqual(t, newScheduler.Next(now), time.Date(2017, time.December, 1, 8, 50, 0, 0, time.UTC))
+}
+
+func TestSchedulerNext_DSTTransition(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable in this environment: %s", err)
+	}
+
+	// 2023-03-12 is the US spring-forward date: 01:59:59 is immediately
+	// followed by 03:00:00 local time, so the grid slot that would otherwise
+	// land on 02:00 doesn't exist.
+	s := Scheduler{SecondsBetweenCaptures: 30 * 60, OffsetWithinHour: 0}
+	now := time.Date(2023, time.March, 12, 1, 59, 30, 0, loc)
+
+	next := s.Next(now)
+	ensure.DeepEqual(t, next.Hour(), 3)
+	ensure.DeepEqual(t, next.Minute(), 0)
+	ensure.True(t, next.After(now))
+}
@@ -1,14 +1,45 @@
 package timelapse
 
 import (
-	"log"
+	"context"
+	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/detection"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/events"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/exif"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/log"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/metrics"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/publish"
 )
 
-func New(folder string, initialSettings conf.Settings, configUpdatedChan <-chan conf.Settings) (*Timelapse, error) {
+// captureContext builds a context bounding a single camera invocation by
+// settings.CaptureTimeoutSeconds, falling back to the package default for a
+// legacy/unset (<= 0) value.
+func captureContext(settings conf.Settings) (context.Context, context.CancelFunc) {
+	timeout := settings.CaptureTimeoutSeconds
+	if timeout <= 0 {
+		timeout = conf.DefaultCaptureTimeoutSeconds
+	}
+	return context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+}
+
+// ConfigurePacerFromSettings applies settings.MinCaptureIntervalSeconds and
+// MaxBackoffSeconds to the shared capture pacer, falling back to the package
+// defaults for a legacy/unset (<= 0) MaxBackoffSeconds so backoff never
+// degenerates to zero.
+func ConfigurePacerFromSettings(settings conf.Settings) {
+	maxBackoff := settings.MaxBackoffSeconds
+	if maxBackoff <= 0 {
+		maxBackoff = conf.DefaultMaxBackoffSeconds
+	}
+	ConfigurePacer(time.Duration(settings.MinCaptureIntervalSeconds)*time.Second, time.Duration(maxBackoff)*time.Second)
+}
+
+func New(folder string, initialSettings conf.Settings, configUpdatedChan <-chan conf.Settings, eventer events.Eventer) (*Timelapse, error) {
 	_, err := os.Stat(folder)
 	createFolder := err != nil && os.IsNotExist(err)
 
@@ -19,111 +50,289 @@ func New(folder string, initialSettings conf.Settings, configUpdatedChan <-chan
 	}
 	// Assume folder exists
 
+	if eventer == nil {
+		eventer = events.NewNullEventer()
+	}
+
 	return &Timelapse{
 		Folder:              folder,
 		Settings:            initialSettings,
 		ConfigUpdateChannel: configUpdatedChan,
+		Eventer:             eventer,
 	}, nil
 }
 
+// recordCapture writes a CAPTURE or CAPTURE_ERROR event for a just-attempted
+// capture, carrying the photo path on success or the error message on
+// failure.
+func (t Timelapse) recordCapture(photoPath string, err error) {
+	if err != nil {
+		t.Eventer.Write(events.Event{Type: events.CaptureError, Timestamp: time.Now(), Attributes: map[string]string{
+			"error": err.Error(),
+		}})
+		return
+	}
+	t.Eventer.Write(events.Event{Type: events.Capture, Timestamp: time.Now(), Attributes: map[string]string{
+		"photoPath": photoPath,
+	}})
+}
+
 func (t Timelapse) CapturePeriodically() {
-	offsetDisabled := t.Settings.OffsetWithinHour == -1
-
-	if offsetDisabled {
-		log.Println("Offset is disabled. Will start taking pictures immediately.")
-		go func() {
-			for {
-				camera, err := NewCamera(t.Folder, t.Settings.PhotoResolutionWidth, t.Settings.PhotoResolutionHeight, t.Settings.RotateBy == 180, t.Settings.Quality)
-				if err != nil {
-					log.Printf("Error instantiating camera: %s\n", err)
-					// Sleep for a bit and create a new camera instance on the next iteration.
-				} else {
-					s, err := camera.Capture()
-					if err != nil {
-						log.Printf("Error during capture: %s\n", err.Error())
-					}
-					log.Printf("Photo stored in '%s'. Will sleep for %d seconds.\n", s, t.Settings.SecondsBetweenCaptures)
-				}
-				time.Sleep(time.Duration(t.Settings.SecondsBetweenCaptures) * time.Second)
-			}
-		}()
+	if t.Settings.MotionCaptureEnabled {
+		log.Infof("Motion-triggered capture is enabled. Frames without detected motion will be discarded.\n")
+		go t.captureOnMotion()
+		return
+	}
+
+	if t.Settings.OffsetWithinHour == -1 {
+		log.Infof("Offset is disabled. Will start taking pictures immediately.\n")
+		go t.capturePeriodicallyUnaligned()
 	} else {
-		log.Println("Offset is enabled. Will wait before taking first picture.")
-		go func() {
-			for {
-				t.waitForCapture()
-
-				beforeCapture := time.Now()
-
-				camera, err := NewCamera(t.Folder, t.Settings.PhotoResolutionWidth, t.Settings.PhotoResolutionHeight, t.Settings.RotateBy == 180, t.Settings.Quality)
-				if err != nil {
-					log.Printf("Error instantiating camera: %s\n", err)
-					// Sleep for a bit and create a new camera instance on the next iteration.
-				} else {
-					photoPath, err := camera.Capture()
-					if err != nil {
-						log.Printf("Error during capture: %s\n", err.Error())
-
-						// Sleep for 1s after an error to ensure time changed sufficiently before next invocation of WaitForCapture
-						time.Sleep(time.Duration(1 * time.Second))
-						continue
-					}
-					log.Printf("Photo stored in '%s'\n", photoPath)
-				}
-				timeToCaptureSeconds := time.Now().Unix() - beforeCapture.Unix()
-				log.Printf("Capture took %d seconds\n", timeToCaptureSeconds)
-			}
-		}()
+		log.Infof("Offset is enabled. Will wait before taking first picture.\n")
+		go t.runScheduled()
 	}
 }
 
-func (t *Timelapse) waitForCapture() {
-	secondsUntilFirstCapture := t.secondsToSleepUntilOffset(time.Now())
-	sleepDuration := time.Duration(secondsUntilFirstCapture) * time.Second
-	nextCaptureAt := time.Now().Add(sleepDuration)
+// captureOnce instantiates a camera, takes one picture, and records,
+// tags, and publishes the result. Errors are logged and otherwise
+// swallowed so a single failed capture never stops the run loop.
+func (t Timelapse) captureOnce() (string, error) {
+	camera, err := NewCamera(t.Folder, t.Settings.PhotoResolutionWidth, t.Settings.PhotoResolutionHeight, t.Settings.RotateBy == 180, t.Settings.Quality, t.Settings.CaptureBackend, t.Settings.RTSPURL, StillTuning{
+		Contrast:       t.Settings.Contrast,
+		Saturation:     t.Settings.Saturation,
+		Sharpness:      t.Settings.Sharpness,
+		Brightness:     t.Settings.Brightness,
+		ExposureMode:   t.Settings.ExposureMode,
+		AWBMode:        t.Settings.AWBMode,
+		ISO:            t.Settings.ISO,
+		ShutterSpeedUs: t.Settings.ShutterSpeedUs,
+		EVCompensation: t.Settings.EVCompensation,
+		Denoise:        t.Settings.Denoise,
+		TextOverlay:    t.Settings.TextOverlay,
+	})
+	if err != nil {
+		log.Errorf("Error instantiating camera: %s\n", err)
+		t.Eventer.Write(events.Event{Type: events.CameraInitError, Timestamp: time.Now(), Attributes: map[string]string{"error": err.Error()}})
+		return "", err
+	}
+	defer camera.Close()
+
+	ctx, cancel := captureContext(t.Settings)
+	defer cancel()
 
-	log.Printf("Will take the next picture in %d seconds at %v.\n", secondsUntilFirstCapture, nextCaptureAt)
+	metrics.RecordCaptureAttempt()
+	photoPath, err := camera.Capture(ctx)
+	metrics.RecordCaptureOutcome(err)
+	t.recordCapture(photoPath, err)
+	if err != nil {
+		log.Errorf("Error during capture: %s\n", err.Error())
+		return "", err
+	}
 
+	t.embedCaptureMetadata(photoPath)
+	// Publish off the hot path: it makes outbound HTTP requests to follower
+	// instances, and a slow or unresponsive one must not delay the next
+	// scheduled capture (see publish.httpClient for the request-side
+	// timeout backing this up).
+	go t.publishCapture(photoPath)
+	log.Infof("Photo stored in '%s'\n", photoPath)
+	return photoPath, nil
+}
+
+// capturePeriodicallyUnaligned runs captures at a fixed interval with no
+// hour-of-day alignment, compensating for how long each capture took so the
+// effective interval between shots stays close to SecondsBetweenCaptures
+// even when a capture is slow.
+func (t Timelapse) capturePeriodicallyUnaligned() {
 	for {
-		secondsUntilFirstCapture := t.secondsToSleepUntilOffset(time.Now())
-		if secondsUntilFirstCapture == 0 {
-			// Game time!
-			break
-		}
-		if t.Settings.DebugEnabled {
-			log.Printf("Sleeping for 1 second. Seconds left: %d. Time: %s.\n", secondsUntilFirstCapture, time.Now())
+		before := time.Now()
+		t.captureOnce()
+
+		sleepFor := time.Duration(t.Settings.SecondsBetweenCaptures)*time.Second - time.Since(before)
+		if sleepFor < 0 {
+			sleepFor = 0
 		}
+		time.Sleep(sleepFor)
+	}
+}
+
+// runScheduled sleeps until each Scheduler target in turn using a
+// resettable timer, so a new configuration arriving mid-sleep can rebuild
+// the grid without losing the pending tick. If a capture overruns its slot
+// and the immediately following slot has already passed, a CAPTURE_MISSED
+// event is logged and the loop jumps straight to the next grid slot instead
+// of piling up missed captures.
+func (t *Timelapse) runScheduled() {
+	scheduler := Scheduler{SecondsBetweenCaptures: t.Settings.SecondsBetweenCaptures, OffsetWithinHour: t.Settings.OffsetWithinHour}
+	target := scheduler.Next(time.Now())
+	timer := time.NewTimer(time.Until(target))
+
+	for {
 		select {
 		case newConfig := <-t.ConfigUpdateChannel:
-			log.Printf("Received new configuration: %s\n", newConfig)
+			log.Infof("Received new configuration: %s\n", newConfig)
+			t.Eventer.Write(events.Event{Type: events.ConfigUpdate, Timestamp: time.Now(), Attributes: map[string]string{
+				"settings": newConfig.String(),
+			}})
 			t.Settings = newConfig
-			break
-		case <-time.After(time.Duration(1 * time.Second)):
-			break
+			ConfigurePacerFromSettings(newConfig)
+
+			scheduler = Scheduler{SecondsBetweenCaptures: newConfig.SecondsBetweenCaptures, OffsetWithinHour: newConfig.OffsetWithinHour}
+			target = scheduler.Next(time.Now())
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(time.Until(target))
+
+		case <-timer.C:
+			expectedNext := target.Add(time.Duration(scheduler.SecondsBetweenCaptures) * time.Second)
+			t.captureOnce()
+
+			next := scheduler.Next(time.Now())
+			if next.After(expectedNext) {
+				log.Errorf("Missed capture target %v; jumping to next slot %v\n", expectedNext, next)
+				t.Eventer.Write(events.Event{Type: events.CaptureMissed, Timestamp: time.Now(), Attributes: map[string]string{
+					"missedTarget": expectedNext.Format(time.RFC3339),
+				}})
+			}
+			target = next
+			timer.Reset(time.Until(target))
 		}
+	}
+}
 
+// embedCaptureMetadata writes DateTimeOriginal, the configured static GPS
+// location, camera make/model, the configured ISO/shutter speed (if set),
+// and (if object detection is enabled) the detection summary into the
+// just-captured JPEG's EXIF tags. Failures are logged and otherwise ignored
+// so a metadata problem never fails a capture.
+func (t Timelapse) embedCaptureMetadata(photoPath string) {
+	tags := exif.Tags{
+		DateTimeOriginal: time.Now(),
+		Make:             t.Settings.CameraMake,
+		Model:            t.Settings.CameraModel,
+	}
+	if t.Settings.ISO != 0 {
+		tags.ISOSpeedRatings = t.Settings.ISO
+	}
+	if t.Settings.ShutterSpeedUs != 0 {
+		tags.ExposureTime = time.Duration(t.Settings.ShutterSpeedUs) * time.Microsecond
+	}
+	if t.Settings.GPSEnabled {
+		tags.HasGPS = true
+		tags.GPSLatitude = t.Settings.GPSLatitude
+		tags.GPSLongitude = t.Settings.GPSLongitude
+	}
+	if t.Settings.ObjectDetectionEnabled {
+		if result, err := detection.AnalyzePhoto(photoPath); err == nil {
+			tags.UserComment = result.Summary
+			metrics.RecordDetection(result)
+		} else {
+			log.Debugf("Skipping detection summary in EXIF for '%s': %s\n", photoPath, err.Error())
+		}
+	}
+	if err := exif.Embed(photoPath, tags); err != nil {
+		log.Errorf("Failed to embed EXIF metadata into '%s': %s\n", photoPath, err.Error())
 	}
 }
 
-func (t Timelapse) secondsToSleepUntilOffset(currentTime time.Time) int {
-	picturesPerHour := 3600 / t.Settings.SecondsBetweenCaptures
+// publishCapture announces a newly captured photo to the configured
+// ActivityPub/Fediverse followers (see the publish package), attaching the
+// detection summary when enabled. Failures are logged and otherwise ignored
+// so a delivery problem never fails a capture.
+func (t Timelapse) publishCapture(photoPath string) {
+	if !t.Settings.PublishEnabled {
+		return
+	}
+	summary := ""
+	if t.Settings.PublishAttachDetectionSummary && t.Settings.ObjectDetectionEnabled {
+		if result, err := detection.AnalyzePhoto(photoPath); err == nil {
+			summary = result.Summary
+		}
+	}
+	photoURL := fmt.Sprintf("https://%s/file/%s", t.Settings.PublishDomain, filepath.Base(photoPath))
+	if err := publish.Publish(t.Settings, photoURL, summary); err != nil {
+		log.Errorf("Failed to publish capture '%s': %s\n", photoPath, err.Error())
+	}
+}
 
-	secondsIntoCurrentHour := currentTime.Minute()*60 + currentTime.Second()
+// captureOnMotion polls the camera every SecondsBetweenCaptures and only
+// keeps a frame when detection.DetectMotion finds enough change against the
+// last kept frame. The first captured frame is always kept to establish a
+// baseline, and a kept frame suppresses further saves for
+// MotionCooldownSeconds even if motion continues, to bound storage growth
+// during sustained activity.
+func (t Timelapse) captureOnMotion() {
+	var lastKeptPhoto string
+	var lastKeptAt time.Time
 
-	for i := 0; i < int(picturesPerHour); i++ {
-		if i == 0 {
-			if 0 <= secondsIntoCurrentHour && secondsIntoCurrentHour <= t.Settings.OffsetWithinHour {
-				return t.Settings.OffsetWithinHour - secondsIntoCurrentHour
-			}
+	for {
+		camera, err := NewCamera(t.Folder, t.Settings.PhotoResolutionWidth, t.Settings.PhotoResolutionHeight, t.Settings.RotateBy == 180, t.Settings.Quality, t.Settings.CaptureBackend, t.Settings.RTSPURL, StillTuning{
+			Contrast:       t.Settings.Contrast,
+			Saturation:     t.Settings.Saturation,
+			Sharpness:      t.Settings.Sharpness,
+			Brightness:     t.Settings.Brightness,
+			ExposureMode:   t.Settings.ExposureMode,
+			AWBMode:        t.Settings.AWBMode,
+			ISO:            t.Settings.ISO,
+			ShutterSpeedUs: t.Settings.ShutterSpeedUs,
+			EVCompensation: t.Settings.EVCompensation,
+			Denoise:        t.Settings.Denoise,
+			TextOverlay:    t.Settings.TextOverlay,
+		})
+		if err != nil {
+			log.Errorf("Error instantiating camera: %s\n", err)
+			t.Eventer.Write(events.Event{Type: events.CameraInitError, Timestamp: time.Now(), Attributes: map[string]string{"error": err.Error()}})
+			time.Sleep(time.Duration(t.Settings.SecondsBetweenCaptures) * time.Second)
+			continue
 		}
 
-		lowerBoundary := t.Settings.OffsetWithinHour + (i-1)*t.Settings.SecondsBetweenCaptures
-		upperBoundary := t.Settings.OffsetWithinHour + (i)*t.Settings.SecondsBetweenCaptures
+		ctx, cancel := captureContext(t.Settings)
+		metrics.RecordCaptureAttempt()
+		photoPath, err := camera.Capture(ctx)
+		cancel()
+		camera.Close()
+		metrics.RecordCaptureOutcome(err)
+		t.recordCapture(photoPath, err)
+		if err != nil {
+			log.Errorf("Error during capture: %s\n", err.Error())
+			time.Sleep(time.Duration(t.Settings.SecondsBetweenCaptures) * time.Second)
+			continue
+		}
 
-		if lowerBoundary <= secondsIntoCurrentHour && secondsIntoCurrentHour <= upperBoundary {
-			return upperBoundary - secondsIntoCurrentHour
+		keep := t.shouldKeepMotionFrame(lastKeptPhoto, photoPath, lastKeptAt)
+		if keep {
+			t.embedCaptureMetadata(photoPath)
+			go t.publishCapture(photoPath) // see captureOnce's publishCapture call for why
+			lastKeptPhoto = photoPath
+			lastKeptAt = time.Now()
+			log.Infof("Motion detected, kept frame '%s'\n", photoPath)
+		} else if err := os.Remove(photoPath); err != nil {
+			log.Errorf("Failed to discard frame without motion '%s': %s\n", photoPath, err.Error())
 		}
+
+		time.Sleep(time.Duration(t.Settings.SecondsBetweenCaptures) * time.Second)
+	}
+}
+
+func (t Timelapse) shouldKeepMotionFrame(lastKeptPhoto, photoPath string, lastKeptAt time.Time) bool {
+	if lastKeptPhoto == "" {
+		return true
+	}
+	if !lastKeptAt.IsZero() && time.Since(lastKeptAt) < time.Duration(t.Settings.MotionCooldownSeconds)*time.Second {
+		return false
 	}
 
-	return 3600 - secondsIntoCurrentHour + t.Settings.OffsetWithinHour
+	config := detection.MotionConfig{
+		GridCols:           t.Settings.MotionGridCols,
+		GridRows:           t.Settings.MotionGridRows,
+		CellDeltaThreshold: t.Settings.MotionCellDeltaThreshold,
+		ChangedCellRatio:   t.Settings.MotionChangedCellRatio,
+	}
+	result, err := detection.DetectMotion(lastKeptPhoto, photoPath, config)
+	if err != nil {
+		log.Errorf("Motion detection failed, keeping frame defensively: %s\n", err.Error())
+		return true
+	}
+	return result.Motion
 }
@@ -0,0 +1,78 @@
+package video
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// nativeEncoder shells out to an ffmpeg binary found on PATH. It's the
+// fallback used when the module wasn't built with the ffmpegwasm tag, or
+// when the embedded WASM runtime failed to initialize.
+type nativeEncoder struct {
+	binaryPath string
+}
+
+func newNativeEncoder() Encoder {
+	path, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil
+	}
+	return &nativeEncoder{binaryPath: path}
+}
+
+func (e *nativeEncoder) Encode(framePaths []string, fps, width, height int, w io.Writer, progress chan<- float64) error {
+	if progress != nil {
+		defer close(progress)
+	}
+	if len(framePaths) == 0 {
+		return fmt.Errorf("no frames to encode")
+	}
+
+	listFile, err := writeConcatList(framePaths)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(listFile)
+
+	cmd := exec.Command(e.binaryPath,
+		"-y",
+		"-f", "concat", "-safe", "0", "-i", listFile,
+		"-r", fmt.Sprintf("%d", fps),
+		"-vf", fmt.Sprintf("scale=%d:%d", width, height),
+		"-c:v", "libx264", "-pix_fmt", "yuv420p",
+		"-movflags", "frag_keyframe+empty_moov",
+		"-f", "mp4", "pipe:1",
+	)
+	cmd.Stdout = w
+	err = cmd.Run()
+	// ffmpeg's own per-frame progress isn't parsed here, so the fallback can
+	// only report completion rather than incremental progress.
+	if progress != nil && err == nil {
+		progress <- 1.0
+	}
+	return err
+}
+
+// writeConcatList writes framePaths to a temporary file in the format
+// ffmpeg's "concat" demuxer expects.
+func writeConcatList(framePaths []string) (string, error) {
+	f, err := os.CreateTemp("", "timelapse-frames-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	for _, p := range framePaths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return "", err
+		}
+		if _, err := fmt.Fprintf(f, "file '%s'\n", abs); err != nil {
+			return "", err
+		}
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,397 @@
+package video
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/conf"
+	"github.com/ingojaeckel/go-raspberry-pi-timelapse/files"
+)
+
+// rendersDirName is where render job metadata and finished videos are
+// persisted, under conf.StorageFolder, so both survive process restarts
+// (see Renderer.load).
+const rendersDirName = "renders"
+
+// Render job statuses.
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// codecs maps the RenderRequest.Codec values this endpoint accepts to the
+// ffmpeg video codec and container/file extension they produce.
+var codecs = map[string]struct{ ffmpegCodec, extension string }{
+	"h264": {"libx264", "mp4"},
+	"vp9":  {"libvpx-vp9", "webm"},
+}
+
+// defaultCodec is used when RenderRequest.Codec is empty or unrecognized.
+const defaultCodec = "h264"
+
+// RenderRequest is the POST /video/render body. From/To are RFC3339
+// timestamps bounding which captured photos (by ModTimeEpoch) go into the
+// video; either may be left empty for an open-ended bound. Resolution is
+// one of the Resolutions keys; Codec is one of the codecs keys.
+type RenderRequest struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Framerate  int    `json:"framerate"`
+	Codec      string `json:"codec"`
+	Bitrate    string `json:"bitrate"` // e.g. "4M"; passed straight to ffmpeg's -b:v
+	Resolution string `json:"resolution"`
+	Deflicker  bool   `json:"deflicker"`
+}
+
+// RenderJob tracks one /video/render invocation. It's persisted as JSON
+// under StorageFolder/renders/<ID>.json after every update, so GET
+// /video/jobs/{id} can report on it (and GET /video/{id} can find the
+// finished file) even across a restart.
+type RenderJob struct {
+	ID              string  `json:"id"`
+	Status          string  `json:"status"`
+	Progress        float64 `json:"progress"` // 0..1
+	TotalFrames     int     `json:"totalFrames"`
+	ProcessedFrames int     `json:"processedFrames"`
+	OutputFile      string  `json:"outputFile"` // filename under StorageFolder/renders/, set once Status is done
+	Error           string  `json:"error,omitempty"`
+	CreatedAt       int64   `json:"createdAt"` // unix seconds
+	UpdatedAt       int64   `json:"updatedAt"` // unix seconds
+}
+
+// runningJob tracks the in-memory state of a render that's still in
+// flight, so the watchdog can find and cancel it.
+type runningJob struct {
+	cancel    context.CancelFunc
+	startedAt time.Time
+}
+
+// Renderer assembles captured JPEGs into an MP4/WebM video asynchronously,
+// persisting job state and finished videos under storageFolder/renders/,
+// and watches in-flight jobs for one exceeding maxDuration so a stuck
+// ffmpeg process doesn't run forever.
+type Renderer struct {
+	storageFolder string
+	maxDuration   time.Duration
+
+	mu      sync.Mutex
+	running map[string]*runningJob
+
+	watchdogOnce sync.Once
+}
+
+// NewRenderer constructs a Renderer rooted at storageFolder. maxDuration is
+// the watchdog budget (see conf.Settings.RenderMaxDurationSeconds); <= 0
+// falls back to conf.DefaultRenderMaxDurationSeconds.
+func NewRenderer(storageFolder string, maxDuration time.Duration) *Renderer {
+	if maxDuration <= 0 {
+		maxDuration = time.Duration(conf.DefaultRenderMaxDurationSeconds) * time.Second
+	}
+	return &Renderer{
+		storageFolder: storageFolder,
+		maxDuration:   maxDuration,
+		running:       map[string]*runningJob{},
+	}
+}
+
+func (r *Renderer) rendersDir() string {
+	return filepath.Join(r.storageFolder, rendersDirName)
+}
+
+func (r *Renderer) jobPath(id string) string {
+	return filepath.Join(r.rendersDir(), id+".json")
+}
+
+// Start validates req, registers a queued RenderJob, and renders it on a
+// background goroutine. It returns as soon as the job is queryable by ID.
+func (r *Renderer) Start(req RenderRequest) (*RenderJob, error) {
+	r.watchdogOnce.Do(func() { go r.watch() })
+
+	if _, ok := codecs[req.Codec]; !ok {
+		req.Codec = defaultCodec
+	}
+	if req.Framerate <= 0 {
+		req.Framerate = conf.DefaultVideoFps
+	}
+	if _, ok := Resolutions[req.Resolution]; !ok {
+		req.Resolution = conf.DefaultVideoResolution
+	}
+
+	framePaths, err := r.framesInRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	if len(framePaths) == 0 {
+		return nil, fmt.Errorf("no captured photos in the requested range")
+	}
+
+	if err := os.MkdirAll(r.rendersDir(), 0755); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &RenderJob{
+		ID:          fmt.Sprintf("%d", now.UnixNano()),
+		Status:      StatusQueued,
+		TotalFrames: len(framePaths),
+		CreatedAt:   now.Unix(),
+		UpdatedAt:   now.Unix(),
+	}
+	if err := r.save(job); err != nil {
+		return nil, err
+	}
+
+	go r.render(job, req, framePaths)
+
+	return job, nil
+}
+
+// Job returns job id's state as persisted in its JSON file under renders/,
+// so jobs from before a restart remain queryable.
+func (r *Renderer) Job(id string) (*RenderJob, bool, error) {
+	return r.load(id)
+}
+
+// OutputPath returns the absolute path to job's finished video. Callers
+// must check job.Status == StatusDone first.
+func (r *Renderer) OutputPath(job *RenderJob) string {
+	return filepath.Join(r.rendersDir(), job.OutputFile)
+}
+
+// framesInRange lists storageFolder's captured photos, keeping only those
+// whose ModTimeEpoch falls within [from, to] (RFC3339, either may be
+// empty), sorted oldest-first to match their capture order.
+func (r *Renderer) framesInRange(from, to string) ([]string, error) {
+	var fromUnix, toUnix int64
+	if from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+		fromUnix = parsed.Unix()
+	}
+	if to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to: %w", err)
+		}
+		toUnix = parsed.Unix()
+	}
+
+	list, err := files.ListFiles(r.storageFolder, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, f := range list {
+		if !strings.HasSuffix(strings.ToLower(f.Name), ".jpg") {
+			continue
+		}
+		if fromUnix != 0 && f.ModTimeEpoch < fromUnix {
+			continue
+		}
+		if toUnix != 0 && f.ModTimeEpoch > toUnix {
+			continue
+		}
+		paths = append(paths, filepath.Join(r.storageFolder, f.Name))
+	}
+	return paths, nil
+}
+
+// render runs job to completion (or failure), persisting its progress as
+// ffmpeg reports frames encoded. It's the watchdog's job to cancel ctx if
+// this takes longer than r.maxDuration.
+func (r *Renderer) render(job *RenderJob, req RenderRequest, framePaths []string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r.mu.Lock()
+	r.running[job.ID] = &runningJob{cancel: cancel, startedAt: time.Now()}
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.running, job.ID)
+		r.mu.Unlock()
+	}()
+
+	job.Status = StatusRunning
+	_ = r.save(job)
+
+	if err := r.runFFmpeg(ctx, job, req, framePaths); err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		job.UpdatedAt = time.Now().Unix()
+		_ = r.save(job)
+		return
+	}
+
+	job.Status = StatusDone
+	job.Progress = 1
+	job.UpdatedAt = time.Now().Unix()
+	_ = r.save(job)
+}
+
+// frameProgressPattern matches ffmpeg stderr progress lines, e.g.
+// "frame=  123 fps= 45 q=28.0 size=...".
+var frameProgressPattern = regexp.MustCompile(`frame=\s*(\d+)`)
+
+func (r *Renderer) runFFmpeg(ctx context.Context, job *RenderJob, req RenderRequest, framePaths []string) error {
+	binaryPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ErrNoEncoderAvailable
+	}
+
+	listFile, err := writeConcatList(framePaths)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(listFile)
+
+	dimensions := Resolutions[req.Resolution]
+	codec := codecs[req.Codec]
+	outputFile := job.ID + "." + codec.extension
+	outputPath := filepath.Join(r.rendersDir(), outputFile)
+
+	var filters []string
+	filters = append(filters, fmt.Sprintf("scale=%d:%d", dimensions[0], dimensions[1]))
+	if req.Deflicker {
+		filters = append(filters, "deflicker")
+	}
+
+	args := []string{
+		"-y",
+		"-f", "concat", "-safe", "0", "-i", listFile,
+		"-r", strconv.Itoa(req.Framerate),
+		"-vf", strings.Join(filters, ","),
+		"-c:v", codec.ffmpegCodec, "-pix_fmt", "yuv420p",
+	}
+	if req.Bitrate != "" {
+		args = append(args, "-b:v", req.Bitrate)
+	}
+	args = append(args, outputPath)
+
+	cmd := exec.CommandContext(ctx, binaryPath, args...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	go r.watchProgress(job, stderr)
+
+	if err := cmd.Wait(); err != nil {
+		os.Remove(outputPath)
+		if ctx.Err() == context.Canceled {
+			return fmt.Errorf("render exceeded its %s time budget and was killed", r.maxDuration)
+		}
+		return fmt.Errorf("ffmpeg failed: %w", err)
+	}
+
+	job.OutputFile = outputFile
+	return nil
+}
+
+// watchProgress scans ffmpeg's stderr for "frame=" lines and persists job's
+// progress as they arrive.
+func (r *Renderer) watchProgress(job *RenderJob, stderr io.ReadCloser) {
+	scanner := bufio.NewScanner(stderr)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	scanner.Split(scanLinesOrCarriageReturns)
+	for scanner.Scan() {
+		match := frameProgressPattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		processed, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		job.ProcessedFrames = processed
+		if job.TotalFrames > 0 {
+			job.Progress = float64(processed) / float64(job.TotalFrames)
+			if job.Progress > 1 {
+				job.Progress = 1
+			}
+		}
+		job.UpdatedAt = time.Now().Unix()
+		_ = r.save(job)
+	}
+}
+
+// scanLinesOrCarriageReturns splits on '\n' or '\r', since ffmpeg redraws
+// its progress line with carriage returns rather than newlines.
+func scanLinesOrCarriageReturns(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	for i, b := range data {
+		if b == '\n' || b == '\r' {
+			return i + 1, data[:i], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	if atEOF {
+		return 0, nil, io.EOF
+	}
+	return 0, nil, nil
+}
+
+// watch periodically kills any in-flight render whose ffmpeg process has
+// run longer than r.maxDuration, the same "keepalive" idea as external
+// media-processing job runners use to bound a single job's wall-clock cost.
+func (r *Renderer) watch() {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.mu.Lock()
+		for id, rj := range r.running {
+			if time.Since(rj.startedAt) > r.maxDuration {
+				rj.cancel()
+				delete(r.running, id)
+			}
+		}
+		r.mu.Unlock()
+	}
+}
+
+// watchdogInterval is how often Renderer.watch checks in-flight jobs
+// against their time budget.
+const watchdogInterval = 10 * time.Second
+
+func (r *Renderer) save(job *RenderJob) error {
+	b, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.jobPath(job.ID), b, 0644)
+}
+
+func (r *Renderer) load(id string) (*RenderJob, bool, error) {
+	b, err := os.ReadFile(r.jobPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	var job RenderJob
+	if err := json.Unmarshal(b, &job); err != nil {
+		return nil, false, err
+	}
+	return &job, true, nil
+}
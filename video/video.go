@@ -0,0 +1,40 @@
+// Package video assembles captured JPEG frames into an H.264/MP4 timelapse
+// video with a native ffmpeg binary on PATH. NewEncoder asks newWasmEncoder
+// first, but no embedded ffmpeg WebAssembly runtime ships with this package:
+// newWasmEncoder returns nil with or without the ffmpegwasm build tag.
+package video
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNoEncoderAvailable is the error reported when NewEncoder returns nil
+// or a render job finds no native ffmpeg binary on PATH.
+var ErrNoEncoderAvailable = errors.New("no ffmpeg encoder available (neither the embedded WASM runtime nor a native ffmpeg binary on PATH)")
+
+// Resolutions maps the resolution query param accepted by the /archive/mp4
+// endpoint to pixel dimensions.
+var Resolutions = map[string][2]int{
+	"480p":  {854, 480},
+	"720p":  {1280, 720},
+	"1080p": {1920, 1080},
+}
+
+// Encoder assembles a sequence of JPEG frames into an H.264/MP4 video.
+type Encoder interface {
+	// Encode streams framePaths into an MP4 container at fps frames/second
+	// and the given resolution, writing the result to w. If progress is
+	// non-nil, it receives values in [0,1] as frames are processed and is
+	// closed when encoding finishes.
+	Encode(framePaths []string, fps, width, height int, w io.Writer, progress chan<- float64) error
+}
+
+// NewEncoder returns the best available Encoder, or nil if none could be
+// initialized.
+func NewEncoder() Encoder {
+	if e := newWasmEncoder(); e != nil {
+		return e
+	}
+	return newNativeEncoder()
+}
@@ -0,0 +1,28 @@
+package video
+
+import (
+	"io"
+	"os/exec"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+func TestResolutions(t *testing.T) {
+	dimensions, ok := Resolutions["1080p"]
+	ensure.True(t, ok)
+	ensure.DeepEqual(t, [2]int{1920, 1080}, dimensions)
+
+	_, ok = Resolutions["240p"]
+	ensure.False(t, ok)
+}
+
+func TestNativeEncoderRequiresFrames(t *testing.T) {
+	path, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		t.Skip("ffmpeg not available on PATH")
+	}
+	e := &nativeEncoder{binaryPath: path}
+	err = e.Encode(nil, 24, 1920, 1080, io.Discard, nil)
+	ensure.NotNil(t, err)
+}
@@ -0,0 +1,14 @@
+//go:build ffmpegwasm
+// +build ffmpegwasm
+
+package video
+
+// newWasmEncoder would run a precompiled, statically-linked ffmpeg build
+// targeting wasm32-wasi via wazero, so a binary built with the ffmpegwasm
+// tag needs no system ffmpeg. No such binary is checked into this tree
+// (producing one is an out-of-band cross-compile step), so this always
+// returns nil and NewEncoder falls through to the native ffmpeg-on-PATH
+// encoder instead.
+func newWasmEncoder() Encoder {
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !ffmpegwasm
+
+package video
+
+// newWasmEncoder is a no-op without the ffmpegwasm build tag, so NewEncoder
+// falls through to the native ffmpeg-on-PATH encoder.
+func newWasmEncoder() Encoder {
+	return nil
+}